@@ -410,3 +410,94 @@ func (ie *IndexExpression) String() string {
 }
 
 func (ie *IndexExpression) ExpressionNode() {}
+
+// a[low:high], either bound may be left out
+type SliceExpression struct {
+    Token    token.Token // the '[' token
+    Left     Expression
+    Low      Expression
+    High     Expression
+}
+
+func (se *SliceExpression) TokenLiteral() string {
+    return se.Token.Literal
+}
+
+func (se *SliceExpression) String() string {
+    var out bytes.Buffer
+
+    out.WriteString("(")
+    out.WriteString(se.Left.String())
+    out.WriteString("[")
+    if se.Low != nil {
+        out.WriteString(se.Low.String())
+    }
+    out.WriteString(":")
+    if se.High != nil {
+        out.WriteString(se.High.String())
+    }
+    out.WriteString("]")
+    out.WriteString(")")
+
+    return out.String()
+}
+
+func (se *SliceExpression) ExpressionNode() {}
+
+// obj.field, reads the string key "field" of a hash
+type FieldExpression struct {
+    Token    token.Token // the '.' token
+    Left     Expression
+    Field    *Identifier
+}
+
+func (fe *FieldExpression) TokenLiteral() string {
+    return fe.Token.Literal
+}
+
+func (fe *FieldExpression) String() string {
+    var out bytes.Buffer
+
+    out.WriteString("(")
+    out.WriteString(fe.Left.String())
+    out.WriteString(".")
+    out.WriteString(fe.Field.String())
+    out.WriteString(")")
+
+    return out.String()
+}
+
+func (fe *FieldExpression) ExpressionNode() {}
+
+// receiver.method(args), calls the hash field `method` of the receiver if
+// it has one, and method(receiver, args) otherwise
+type MethodCallExpression struct {
+    Token     token.Token // the '(' token
+    Receiver  Expression
+    Method    *Identifier
+    Arguments []Expression
+}
+
+func (mc *MethodCallExpression) TokenLiteral() string {
+    return mc.Token.Literal
+}
+
+func (mc *MethodCallExpression) String() string {
+    var out bytes.Buffer
+
+    args := []string{}
+    for _, a := range mc.Arguments {
+        args = append(args, a.String())
+    }
+
+    out.WriteString(mc.Receiver.String())
+    out.WriteString(".")
+    out.WriteString(mc.Method.String())
+    out.WriteString("(")
+    out.WriteString(strings.Join(args, ", "))
+    out.WriteString(")")
+
+    return out.String()
+}
+
+func (mc *MethodCallExpression) ExpressionNode() {}
@@ -37,9 +37,22 @@ const (
     OpArray
     OpHash
     OpIndex
+    OpSlice          // left[low:high], missing bounds are pushed as null
     OpCall
+    OpCallMethod     // receiver.method(args), operands: method name constant, number of args
+    OpTailCall       // OpCall in tail position, replaces the frame of the caller
     OpReturnValue    // value is on the top of the stack
     OpReturn         // nothing return
+
+    // superinstructions, the compiler lowers common sequences into these
+    OpGetLocal0      // OpGetLocal 0
+    OpGetLocal1      // OpGetLocal 1
+    OpGetLocal2      // OpGetLocal 2
+    OpGetLocal3      // OpGetLocal 3
+    OpAddConst       // OpConstant, OpAdd
+    OpLessThanJump   // OpLessThan, OpJumpNotTruthy: jumps unless left < right
+    OpCall0          // OpCall 0
+    OpCall1          // OpCall 1
 )
 
 const (
@@ -83,9 +96,20 @@ var definitions = map[Opcode]*Definition {
     OpArray:         {"OpArray",         []int{2}},
     OpHash:          {"OpHash",          []int{2}},
     OpIndex:         {"OpIndex",         []int{}},
+    OpSlice:         {"OpSlice",         []int{}},
     OpCall:          {"OpCall",          []int{1}},
+    OpCallMethod:    {"OpCallMethod",    []int{2, 1}},
+    OpTailCall:      {"OpTailCall",      []int{1}},
     OpReturnValue:   {"OpReturnValue",   []int{}},
     OpReturn:        {"OpReturn",        []int{}},
+    OpGetLocal0:     {"OpGetLocal0",     []int{}},
+    OpGetLocal1:     {"OpGetLocal1",     []int{}},
+    OpGetLocal2:     {"OpGetLocal2",     []int{}},
+    OpGetLocal3:     {"OpGetLocal3",     []int{}},
+    OpAddConst:      {"OpAddConst",      []int{2}},
+    OpLessThanJump:  {"OpLessThanJump",  []int{2}},
+    OpCall0:         {"OpCall0",         []int{}},
+    OpCall1:         {"OpCall1",         []int{}},
 }
 
 func (ins Instructions) String() string {
@@ -122,6 +146,8 @@ func (ins Instructions) fmtInstruction(def *Definition, operands []int) string {
         return def.Name
     case 1:
         return fmt.Sprintf("%s %d", def.Name, operands[0])
+    case 2:
+        return fmt.Sprintf("%s %d %d", def.Name, operands[0], operands[1])
     }
 
     return fmt.Sprintf("ERROR: unhandled operandCount for %s\n", def.Name)
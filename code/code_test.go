@@ -13,6 +13,9 @@ func TestMake(t *testing.T) {
         {OpConstant, []int{65534}, []byte{byte(OpConstant), 255, 254}},
         {OpAdd, []int{}, []byte{byte(OpAdd)}},
         {OpGetLocal, []int{255}, []byte{byte(OpGetLocal), 255}},
+        {OpCallMethod, []int{65534, 255}, []byte{byte(OpCallMethod), 255, 254, 255}},
+        {OpGetLocal2, []int{}, []byte{byte(OpGetLocal2)}},
+        {OpAddConst, []int{65534}, []byte{byte(OpAddConst), 255, 254}},
     }
 
     for _, tt := range tests {
@@ -39,12 +42,14 @@ func TestInstructionsString(t *testing.T) {
         Make(OpGetLocal, 1),
         Make(OpConstant, 2),
         Make(OpConstant, 65535),
+        Make(OpCallMethod, 3, 1),
     }
 
     expected := `0000 OpAdd
 0001 OpGetLocal 1
 0003 OpConstant 2
 0006 OpConstant 65535
+0009 OpCallMethod 3 1
 `
     concatted := concatInstructions(instructions)
 
@@ -62,6 +67,7 @@ func TestReadOperands(t *testing.T) {
     }{
         {OpConstant, []int{65535}, 2},
         {OpGetLocal, []int{255}, 1},
+        {OpCallMethod, []int{65535, 255}, 3},
     }
 
     for _, tt := range tests {
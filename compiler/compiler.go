@@ -18,15 +18,28 @@ type CompilationScope struct {
 
     lastInstruction     EmittedInstruction
     previousInstruction EmittedInstruction
+
+    // how many if branches deep the code being compiled is, the lets in a
+    // branch may not run, so their values are not propagated
+    branches            int
 }
 
 type Compiler struct {
     constants    []object.Object
+    // pool indices of the integer and string constants by value, so that
+    // equal literals share one slot
+    interned     map[interface{}]int
 
     symbolTable  *SymbolTable
 
     scopes       []CompilationScope
     scopeIndex   int
+
+    optimizations Optimizations
+    warnings      []string
+
+    // the calls to compile as OpTailCall
+    tailCalls     map[*ast.CallExpression]bool
 }
 
 func New() *Compiler {
@@ -43,9 +56,13 @@ func New() *Compiler {
 
     return &Compiler {
         constants:    []object.Object{},
+        interned:     make(map[interface{}]int),
         symbolTable:  symbolTable,
         scopes:       []CompilationScope{mainScope},
         scopeIndex:   0,
+
+        optimizations: AllOptimizations(),
+        tailCalls:     make(map[*ast.CallExpression]bool),
     }
 }
 
@@ -53,26 +70,41 @@ func NewWithState(s *SymbolTable, constants []object.Object) *Compiler {
     compiler := New()
     compiler.symbolTable = s
     compiler.constants = constants
+    for i, constant := range constants {
+        if key, ok := internKey(constant); ok {
+            compiler.interned[key] = i
+        }
+    }
     return compiler
 }
 
 func (c *Compiler) Compile(node ast.Node) error {
+    if expr, ok := node.(ast.Expression); ok && c.optimizations.ConstantFolding {
+        if value, ok := c.constantValue(expr); ok {
+            c.emitConstant(value)
+            return nil
+        }
+    }
+
     switch node := node.(type) {
     case *ast.Program:
-        for _, s := range node.Statements {
-            err := c.Compile(s)
-            if err != nil {
-                return err
-            }
+        err := c.compileStatements(node.Statements)
+        if err != nil {
+            return err
         }
 
     case *ast.LetStatement:
+        value, isConstant := c.constantValue(node.Value)
+
         err := c.Compile(node.Value)
         if err != nil {
             return err
         }
 
         symbol := c.symbolTable.Define(node.Name.Value)
+        if isConstant && c.optimizations.ConstantFolding && c.scopes[c.scopeIndex].branches == 0 {
+            c.symbolTable.SetConstant(node.Name.Value, value)
+        }
 
         if symbol.Scope == GlobalScope {
             c.emit(code.OpSetGlobal, symbol.Index)
@@ -89,11 +121,9 @@ func (c *Compiler) Compile(node ast.Node) error {
         c.emit(code.OpReturnValue)
 
     case *ast.BlockStatement:
-        for _, s := range node.Statements {
-            err := c.Compile(s)
-            if err != nil {
-                return err
-            }
+        err := c.compileStatements(node.Statements)
+        if err != nil {
+            return err
         }
 
     case *ast.ExpressionStatement:
@@ -161,7 +191,7 @@ func (c *Compiler) Compile(node ast.Node) error {
         c.loadSymbol(symbol)
 
     case *ast.IntegerLiteral:
-        integer := &object.Integer{Value: node.Value}
+        integer := object.NewInteger(node.Value)
         c.emit(code.OpConstant, c.addConstant(integer))
 
     case *ast.StringLiteral:
@@ -176,6 +206,12 @@ func (c *Compiler) Compile(node ast.Node) error {
         }
         
     case *ast.IfExpression:
+        if c.optimizations.ConstantFolding || c.optimizations.DeadCode {
+            if condition, ok := c.constantValue(node.Condition); ok {
+                return c.compileConstantIf(node, condition)
+            }
+        }
+
         err := c.Compile(node.Condition)
         if err != nil {
             return err
@@ -183,15 +219,13 @@ func (c *Compiler) Compile(node ast.Node) error {
 
         jumpNotTruthyPos := c.emit(code.OpJumpNotTruthy, 9999)
 
-        err = c.Compile(node.Consequence)
+        c.scopes[c.scopeIndex].branches++
+
+        err = c.compileBranch(node.Consequence)
         if err != nil {
             return err
         }
 
-        if c.lastInstructionIs(code.OpPop) {
-            c.removeLastPop()
-        }
-
         jumpPos := c.emit(code.OpJump, 9999)
 
         afterConsequencePos := len(c.currentInstructions())
@@ -200,16 +234,14 @@ func (c *Compiler) Compile(node ast.Node) error {
         if node.Alternative == nil {
             c.emit(code.OpNull)
         } else {
-            err = c.Compile(node.Alternative)
+            err = c.compileBranch(node.Alternative)
             if err != nil {
                 return err
             }
-    
-            if c.lastInstructionIs(code.OpPop) {
-                c.removeLastPop()
-            }
         }
 
+        c.scopes[c.scopeIndex].branches--
+
         afterAlternativePos := len(c.currentInstructions())
         c.changeOperand(jumpPos, afterAlternativePos)
 
@@ -260,6 +292,36 @@ func (c *Compiler) Compile(node ast.Node) error {
 
         c.emit(code.OpIndex)
 
+    case *ast.SliceExpression:
+        err := c.Compile(node.Left)
+        if err != nil {
+            return err
+        }
+
+        for _, bound := range []ast.Expression{node.Low, node.High} {
+            if bound == nil {
+                c.emit(code.OpNull)
+                continue
+            }
+
+            err := c.Compile(bound)
+            if err != nil {
+                return err
+            }
+        }
+
+        c.emit(code.OpSlice)
+
+    case *ast.FieldExpression:
+        err := c.Compile(node.Left)
+        if err != nil {
+            return err
+        }
+
+        key := &object.FieldKey{Name: node.Field.Value}
+        c.emit(code.OpConstant, c.addConstant(key))
+        c.emit(code.OpIndex)
+
     case *ast.FunctionLiteral:
         c.enterScope()
 
@@ -267,6 +329,10 @@ func (c *Compiler) Compile(node ast.Node) error {
             c.symbolTable.Define(p.Value)
         }
 
+        if c.optimizations.TailCalls {
+            c.markTailCalls(node.Body)
+        }
+
         err := c.Compile(node.Body)
         if err != nil {
             return err
@@ -302,7 +368,37 @@ func (c *Compiler) Compile(node ast.Node) error {
             }
         }
 
-        c.emit(code.OpCall, len(node.Arguments))
+        if c.tailCalls[node] {
+            c.emit(code.OpTailCall, len(node.Arguments))
+        } else {
+            c.emit(code.OpCall, len(node.Arguments))
+        }
+
+    case *ast.MethodCallExpression:
+        err := c.Compile(node.Receiver)
+        if err != nil {
+            return err
+        }
+
+        // the function to fall back on when the receiver has no such field;
+        // null if there is none in scope, which fails at runtime only if it
+        // is actually needed
+        symbol, ok := c.symbolTable.Resolve(node.Method.Value)
+        if ok {
+            c.loadSymbol(symbol)
+        } else {
+            c.emit(code.OpNull)
+        }
+
+        for _, a := range node.Arguments {
+            err := c.Compile(a)
+            if err != nil {
+                return err
+            }
+        }
+
+        name := &object.String{Value: node.Method.Value}
+        c.emit(code.OpCallMethod, c.addConstant(name), len(node.Arguments))
 
     }
 
@@ -328,10 +424,35 @@ func (c *Compiler) addInstruction(ins []byte) int {
 }
 
 func (c *Compiler) addConstant(object object.Object) int {
+    key, internable := internKey(object)
+    if internable {
+        if i, ok := c.interned[key]; ok {
+            return i
+        }
+    }
+
     c.constants = append(c.constants, object)
+    if internable {
+        c.interned[key] = len(c.constants) - 1
+    }
+
     return len(c.constants) - 1
 }
 
+// integers and strings are immutable, so equal ones can be shared
+func internKey(obj object.Object) (interface{}, bool) {
+    switch obj := obj.(type) {
+    case *object.Integer:
+        return obj.Value, true
+    case *object.String:
+        return obj.Value, true
+    case *object.FieldKey:
+        return *obj, true
+    }
+
+    return nil, false
+}
+
 func (c *Compiler) setLastInstruction(op code.Opcode, pos int) {
     previous := c.scopes[c.scopeIndex].lastInstruction
     last     := EmittedInstruction{Opcode: op, Position: pos}
@@ -399,6 +520,12 @@ func (c *Compiler) enterScope() {
 
 func (c *Compiler) leaveScope() code.Instructions {
     instructions := c.currentInstructions()
+    if c.optimizations.Peephole {
+        instructions = peephole(instructions, false)
+    }
+    if c.optimizations.Superinstructions {
+        instructions = superinstructions(instructions)
+    }
 
     c.scopes = c.scopes[: len(c.scopes) - 1]
     c.scopeIndex--
@@ -425,8 +552,16 @@ type Bytecode struct {
 }
 
 func (c *Compiler) Bytecode() *Bytecode {
+    instructions := c.currentInstructions()
+    if c.optimizations.Peephole {
+        instructions = peephole(instructions, c.scopeIndex == 0)
+    }
+    if c.optimizations.Superinstructions {
+        instructions = superinstructions(instructions)
+    }
+
     return &Bytecode {
-        Instructions: c.currentInstructions(),
+        Instructions: instructions,
         Constants:    c.constants,
     }
 }
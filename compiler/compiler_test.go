@@ -459,14 +459,14 @@ func TestIndexExpressions(t *testing.T) {
     tests := []compilerTestCase{
         {
             input:             "[1, 2, 3][1 + 1]",
-            expectedConstants: []interface{}{1, 2, 3, 1, 1},
+            expectedConstants: []interface{}{1, 2, 3},
             expectedInstructions: []code.Instructions{
                 code.Make(code.OpConstant, 0),
                 code.Make(code.OpConstant, 1),
                 code.Make(code.OpConstant, 2),
                 code.Make(code.OpArray, 3),
-                code.Make(code.OpConstant, 3),
-                code.Make(code.OpConstant, 4),
+                code.Make(code.OpConstant, 0),
+                code.Make(code.OpConstant, 0),
                 code.Make(code.OpAdd),
                 code.Make(code.OpIndex),
                 code.Make(code.OpPop),
@@ -474,13 +474,13 @@ func TestIndexExpressions(t *testing.T) {
         },
         {
             input:             "{1: 2}[2 - 1]",
-            expectedConstants: []interface{}{1, 2, 2, 1},
+            expectedConstants: []interface{}{1, 2},
             expectedInstructions: []code.Instructions{
                 code.Make(code.OpConstant, 0),
                 code.Make(code.OpConstant, 1),
                 code.Make(code.OpHash, 2),
-                code.Make(code.OpConstant, 2),
-                code.Make(code.OpConstant, 3),
+                code.Make(code.OpConstant, 1),
+                code.Make(code.OpConstant, 0),
                 code.Make(code.OpSub),
                 code.Make(code.OpIndex),
                 code.Make(code.OpPop),
@@ -491,6 +491,87 @@ func TestIndexExpressions(t *testing.T) {
     runCompilerTests(t, tests)
 }
 
+func TestSliceExpressions(t *testing.T) {
+    tests := []compilerTestCase{
+        {
+            input:             "[1, 2][0:1]",
+            expectedConstants: []interface{}{1, 2, 0},
+            expectedInstructions: []code.Instructions{
+                code.Make(code.OpConstant, 0),
+                code.Make(code.OpConstant, 1),
+                code.Make(code.OpArray, 2),
+                code.Make(code.OpConstant, 2),
+                code.Make(code.OpConstant, 0),
+                code.Make(code.OpSlice),
+                code.Make(code.OpPop),
+            },
+        },
+        {
+            input:             `"abc"[:2]`,
+            expectedConstants: []interface{}{"abc", 2},
+            expectedInstructions: []code.Instructions{
+                code.Make(code.OpConstant, 0),
+                code.Make(code.OpNull),
+                code.Make(code.OpConstant, 1),
+                code.Make(code.OpSlice),
+                code.Make(code.OpPop),
+            },
+        },
+    }
+
+    runCompilerTests(t, tests)
+}
+
+func TestFieldExpressions(t *testing.T) {
+    tests := []compilerTestCase{
+        {
+            input:             `{"name": 1}.name`,
+            expectedConstants: []interface{}{"name", 1, &object.FieldKey{Name: "name"}},
+            expectedInstructions: []code.Instructions{
+                code.Make(code.OpConstant, 0),
+                code.Make(code.OpConstant, 1),
+                code.Make(code.OpHash, 2),
+                code.Make(code.OpConstant, 2),
+                code.Make(code.OpIndex),
+                code.Make(code.OpPop),
+            },
+        },
+    }
+
+    runCompilerTests(t, tests)
+}
+
+func TestMethodCalls(t *testing.T) {
+    tests := []compilerTestCase{
+        {
+            input:             `[1].push(2)`,
+            expectedConstants: []interface{}{1, 2, "push"},
+            expectedInstructions: []code.Instructions{
+                code.Make(code.OpConstant, 0),
+                code.Make(code.OpArray, 1),
+                code.Make(code.OpGetBuiltin, 4),
+                code.Make(code.OpConstant, 1),
+                code.Make(code.OpCallMethod, 2, 1),
+                code.Make(code.OpPop),
+            },
+        },
+        {
+            input:             `{"f": 1}.f()`,
+            expectedConstants: []interface{}{"f", 1},
+            expectedInstructions: []code.Instructions{
+                code.Make(code.OpConstant, 0),
+                code.Make(code.OpConstant, 1),
+                code.Make(code.OpHash, 2),
+                code.Make(code.OpNull),
+                code.Make(code.OpCallMethod, 0, 0),
+                code.Make(code.OpPop),
+            },
+        },
+    }
+
+    runCompilerTests(t, tests)
+}
+
 func TestFunctions(t *testing.T) {
     tests := []compilerTestCase{
         {
@@ -774,13 +855,555 @@ func TestBuiltins(t *testing.T) {
     runCompilerTests(t, tests)
 }
 
+func TestConstantInterning(t *testing.T) {
+    tests := []compilerTestCase{
+        {
+            input:             `1; "a"; 1; "a"; "1"; fn() { 1 }`,
+            expectedConstants: []interface{}{
+                1,
+                "a",
+                "1",
+                []code.Instructions{
+                    code.Make(code.OpConstant, 0),
+                    code.Make(code.OpReturnValue),
+                },
+            },
+            expectedInstructions: []code.Instructions{
+                code.Make(code.OpConstant, 0),
+                code.Make(code.OpPop),
+                code.Make(code.OpConstant, 1),
+                code.Make(code.OpPop),
+                code.Make(code.OpConstant, 0),
+                code.Make(code.OpPop),
+                code.Make(code.OpConstant, 1),
+                code.Make(code.OpPop),
+                code.Make(code.OpConstant, 2),
+                code.Make(code.OpPop),
+                code.Make(code.OpConstant, 3),
+                code.Make(code.OpPop),
+            },
+        },
+    }
+
+    runCompilerTests(t, tests)
+}
+
+func TestConstantInterningAcrossCompilers(t *testing.T) {
+    symbolTable := NewSymbolTable()
+
+    first := NewWithState(symbolTable, []object.Object{})
+    err := first.Compile(parse(`let a = "name"; 1`))
+    if err != nil {
+        t.Fatalf("compiler error: %s", err)
+    }
+    constants := first.Bytecode().Constants
+
+    second := NewWithState(symbolTable, constants)
+    err = second.Compile(parse(`"name"; 1; 2`))
+    if err != nil {
+        t.Fatalf("compiler error: %s", err)
+    }
+
+    err = testConstants(t, []interface{}{"name", 1, 2}, second.Bytecode().Constants)
+    if err != nil {
+        t.Fatalf("testConstants failed: %s", err)
+    }
+}
+
+func TestConstantFolding(t *testing.T) {
+    tests := []compilerTestCase{
+        {
+            input:             "2 * 60 * 60",
+            expectedConstants: []interface{}{7200},
+            expectedInstructions: []code.Instructions{
+                code.Make(code.OpConstant, 0),
+                code.Make(code.OpPop),
+            },
+        },
+        {
+            input:             "-5; !true; !5; 1 < 2 == true",
+            expectedConstants: []interface{}{-5},
+            expectedInstructions: []code.Instructions{
+                code.Make(code.OpConstant, 0),
+                code.Make(code.OpPop),
+                code.Make(code.OpFalse),
+                code.Make(code.OpPop),
+                code.Make(code.OpFalse),
+                code.Make(code.OpPop),
+                code.Make(code.OpTrue),
+                code.Make(code.OpPop),
+            },
+        },
+        {
+            input:             `"mon" + "key"`,
+            expectedConstants: []interface{}{"monkey"},
+            expectedInstructions: []code.Instructions{
+                code.Make(code.OpConstant, 0),
+                code.Make(code.OpPop),
+            },
+        },
+        {
+            // division by zero is left for the VM, as it has to fail at
+            // runtime, strings compare by value and fold
+            input:             `1 / 0; "a" == "a"`,
+            expectedConstants: []interface{}{1, 0},
+            expectedInstructions: []code.Instructions{
+                code.Make(code.OpConstant, 0),
+                code.Make(code.OpConstant, 1),
+                code.Make(code.OpDiv),
+                code.Make(code.OpPop),
+                code.Make(code.OpTrue),
+                code.Make(code.OpPop),
+            },
+        },
+        {
+            input:             "let x = 60; x * 2",
+            expectedConstants: []interface{}{60, 120},
+            expectedInstructions: []code.Instructions{
+                code.Make(code.OpConstant, 0),
+                code.Make(code.OpSetGlobal, 0),
+                code.Make(code.OpConstant, 1),
+                code.Make(code.OpPop),
+            },
+        },
+        {
+            input:             "let x = 1; let x = len([]); x + 1",
+            expectedConstants: []interface{}{1},
+            expectedInstructions: []code.Instructions{
+                code.Make(code.OpConstant, 0),
+                code.Make(code.OpSetGlobal, 0),
+                code.Make(code.OpGetBuiltin, 0),
+                code.Make(code.OpArray, 0),
+                code.Make(code.OpCall1),
+                code.Make(code.OpSetGlobal, 0),
+                code.Make(code.OpGetGlobal, 0),
+                code.Make(code.OpAddConst, 0),
+                code.Make(code.OpPop),
+            },
+        },
+        {
+            // the let in the branch may not run, so x is no longer known
+            input:             "let x = 1; if (len([])) { let x = 2; }; x",
+            expectedConstants: []interface{}{1, 2},
+            expectedInstructions: []code.Instructions{
+                code.Make(code.OpConstant, 0),
+                code.Make(code.OpSetGlobal, 0),
+                code.Make(code.OpGetBuiltin, 0),
+                code.Make(code.OpArray, 0),
+                code.Make(code.OpCall1),
+                code.Make(code.OpJumpNotTruthy, 25),
+                code.Make(code.OpConstant, 1),
+                code.Make(code.OpSetGlobal, 0),
+                code.Make(code.OpNull),
+                code.Make(code.OpJump, 26),
+                code.Make(code.OpNull),
+                code.Make(code.OpPop),
+                code.Make(code.OpGetGlobal, 0),
+                code.Make(code.OpPop),
+            },
+        },
+        {
+            input:             "let x = 1; fn(x) { let y = 2; x * y }",
+            expectedConstants: []interface{}{
+                1,
+                2,
+                []code.Instructions{
+                    code.Make(code.OpConstant, 1),
+                    code.Make(code.OpSetLocal, 1),
+                    code.Make(code.OpGetLocal0),
+                    code.Make(code.OpConstant, 1),
+                    code.Make(code.OpMul),
+                    code.Make(code.OpReturnValue),
+                },
+            },
+            expectedInstructions: []code.Instructions{
+                code.Make(code.OpConstant, 0),
+                code.Make(code.OpSetGlobal, 0),
+                code.Make(code.OpConstant, 2),
+                code.Make(code.OpPop),
+            },
+        },
+        {
+            input:             "if (1 < 2) { 10 } else { 20 }; if (false) { 30 }; 3333",
+            expectedConstants: []interface{}{10, 3333},
+            expectedInstructions: []code.Instructions{
+                code.Make(code.OpConstant, 0),
+                code.Make(code.OpPop),
+                code.Make(code.OpNull),
+                code.Make(code.OpPop),
+                code.Make(code.OpConstant, 1),
+                code.Make(code.OpPop),
+            },
+        },
+    }
+
+    runOptimizedCompilerTests(t, tests)
+}
+
+func TestConstantFoldingDisabled(t *testing.T) {
+    tests := []compilerTestCase{
+        {
+            input:             "if (true) { 2 * 3 }",
+            expectedConstants: []interface{}{2, 3},
+            expectedInstructions: []code.Instructions{
+                code.Make(code.OpTrue),
+                code.Make(code.OpJumpNotTruthy, 14),
+                code.Make(code.OpConstant, 0),
+                code.Make(code.OpConstant, 1),
+                code.Make(code.OpMul),
+                code.Make(code.OpJump, 15),
+                code.Make(code.OpNull),
+                code.Make(code.OpPop),
+            },
+        },
+    }
+
+    runCompilerTests(t, tests)
+}
+
+func TestDeadCodeElimination(t *testing.T) {
+    tests := []struct {
+        compilerTestCase
+        expectedWarnings []string
+    }{
+        {
+            compilerTestCase{
+                input:             "fn() { return 1; 2; 3 }",
+                expectedConstants: []interface{}{
+                    1,
+                    []code.Instructions{
+                        code.Make(code.OpConstant, 0),
+                        code.Make(code.OpReturnValue),
+                    },
+                },
+                expectedInstructions: []code.Instructions{
+                    code.Make(code.OpConstant, 1),
+                    code.Make(code.OpPop),
+                },
+            },
+            []string{"unreachable code after return: 2"},
+        },
+        {
+            compilerTestCase{
+                input:             "fn(x) { if (x) { return 1; } else { return 2; }; 3 }",
+                expectedConstants: []interface{}{
+                    1,
+                    2,
+                    []code.Instructions{
+                        code.Make(code.OpGetLocal, 0),
+                        code.Make(code.OpJumpNotTruthy, 12),
+                        code.Make(code.OpConstant, 0),
+                        code.Make(code.OpReturnValue),
+                        code.Make(code.OpJump, 16),
+                        code.Make(code.OpConstant, 1),
+                        code.Make(code.OpReturnValue),
+                        code.Make(code.OpReturnValue),
+                    },
+                },
+                expectedInstructions: []code.Instructions{
+                    code.Make(code.OpConstant, 2),
+                    code.Make(code.OpPop),
+                },
+            },
+            []string{"unreachable code after return: 3"},
+        },
+        {
+            compilerTestCase{
+                input:             "fn(x) { if (x) { return 1; }; 2 }",
+                expectedConstants: []interface{}{
+                    1,
+                    2,
+                    []code.Instructions{
+                        code.Make(code.OpGetLocal, 0),
+                        code.Make(code.OpJumpNotTruthy, 12),
+                        code.Make(code.OpConstant, 0),
+                        code.Make(code.OpReturnValue),
+                        code.Make(code.OpJump, 13),
+                        code.Make(code.OpNull),
+                        code.Make(code.OpPop),
+                        code.Make(code.OpConstant, 1),
+                        code.Make(code.OpReturnValue),
+                    },
+                },
+                expectedInstructions: []code.Instructions{
+                    code.Make(code.OpConstant, 2),
+                    code.Make(code.OpPop),
+                },
+            },
+            nil,
+        },
+        {
+            compilerTestCase{
+                input:             "if (false) { 10 } else { 20 }; if (true) { 30 }",
+                expectedConstants: []interface{}{20, 30},
+                expectedInstructions: []code.Instructions{
+                    code.Make(code.OpConstant, 0),
+                    code.Make(code.OpPop),
+                    code.Make(code.OpConstant, 1),
+                    code.Make(code.OpPop),
+                },
+            },
+            []string{"unreachable branch: condition false is always false"},
+        },
+    }
+
+    for _, tt := range tests {
+        runCompilerTestsWith(t, []compilerTestCase{tt.compilerTestCase}, Optimizations{DeadCode: true})
+
+        compiler := New()
+        compiler.SetOptimizations(Optimizations{DeadCode: true})
+        err := compiler.Compile(parse(tt.input))
+        if err != nil {
+            t.Fatalf("compiler error: %s", err)
+        }
+
+        warnings := compiler.Warnings()
+        if len(warnings) != len(tt.expectedWarnings) {
+            t.Fatalf("%s: wrong warnings. want=%q, got=%q", tt.input, tt.expectedWarnings, warnings)
+        }
+        for i, want := range tt.expectedWarnings {
+            if warnings[i] != want {
+                t.Errorf("%s: wrong warning. want=%q, got=%q", tt.input, want, warnings[i])
+            }
+        }
+    }
+}
+
+func TestTailCalls(t *testing.T) {
+    tests := []compilerTestCase{
+        {
+            input:             "fn(f) { f(1) }",
+            expectedConstants: []interface{}{
+                1,
+                []code.Instructions{
+                    code.Make(code.OpGetLocal, 0),
+                    code.Make(code.OpConstant, 0),
+                    code.Make(code.OpTailCall, 1),
+                    code.Make(code.OpReturnValue),
+                },
+            },
+            expectedInstructions: []code.Instructions{
+                code.Make(code.OpConstant, 1),
+                code.Make(code.OpPop),
+            },
+        },
+        {
+            input:             "fn(f) { f(1) + 1 }",
+            expectedConstants: []interface{}{
+                1,
+                []code.Instructions{
+                    code.Make(code.OpGetLocal, 0),
+                    code.Make(code.OpConstant, 0),
+                    code.Make(code.OpCall, 1),
+                    code.Make(code.OpConstant, 0),
+                    code.Make(code.OpAdd),
+                    code.Make(code.OpReturnValue),
+                },
+            },
+            expectedInstructions: []code.Instructions{
+                code.Make(code.OpConstant, 1),
+                code.Make(code.OpPop),
+            },
+        },
+        {
+            input:             "fn(f) { if (f) { return f(f(1)); }; f(2); 3 }",
+            expectedConstants: []interface{}{
+                1,
+                2,
+                3,
+                []code.Instructions{
+                    code.Make(code.OpGetLocal, 0),
+                    code.Make(code.OpJumpNotTruthy, 20),
+                    code.Make(code.OpGetLocal, 0),
+                    code.Make(code.OpGetLocal, 0),
+                    code.Make(code.OpConstant, 0),
+                    code.Make(code.OpCall, 1),
+                    code.Make(code.OpTailCall, 1),
+                    code.Make(code.OpReturnValue),
+                    code.Make(code.OpJump, 21),
+                    code.Make(code.OpNull),
+                    code.Make(code.OpPop),
+                    code.Make(code.OpGetLocal, 0),
+                    code.Make(code.OpConstant, 1),
+                    code.Make(code.OpCall, 1),
+                    code.Make(code.OpPop),
+                    code.Make(code.OpConstant, 2),
+                    code.Make(code.OpReturnValue),
+                },
+            },
+            expectedInstructions: []code.Instructions{
+                code.Make(code.OpConstant, 3),
+                code.Make(code.OpPop),
+            },
+        },
+        {
+            input:             "fn(f, n) { if (n) { f(n) } else { f(f, n) } }",
+            expectedConstants: []interface{}{
+                []code.Instructions{
+                    code.Make(code.OpGetLocal, 1),
+                    code.Make(code.OpJumpNotTruthy, 14),
+                    code.Make(code.OpGetLocal, 0),
+                    code.Make(code.OpGetLocal, 1),
+                    code.Make(code.OpTailCall, 1),
+                    code.Make(code.OpJump, 22),
+                    code.Make(code.OpGetLocal, 0),
+                    code.Make(code.OpGetLocal, 0),
+                    code.Make(code.OpGetLocal, 1),
+                    code.Make(code.OpTailCall, 2),
+                    code.Make(code.OpReturnValue),
+                },
+            },
+            expectedInstructions: []code.Instructions{
+                code.Make(code.OpConstant, 0),
+                code.Make(code.OpPop),
+            },
+        },
+    }
+
+    runCompilerTestsWith(t, tests, Optimizations{TailCalls: true})
+}
+
+func TestSuperinstructions(t *testing.T) {
+    tests := []compilerTestCase{
+        {
+            input:             "fn(a, b, c, d, e) { a; b; c; d; e }",
+            expectedConstants: []interface{}{
+                []code.Instructions{
+                    code.Make(code.OpGetLocal0),
+                    code.Make(code.OpPop),
+                    code.Make(code.OpGetLocal1),
+                    code.Make(code.OpPop),
+                    code.Make(code.OpGetLocal2),
+                    code.Make(code.OpPop),
+                    code.Make(code.OpGetLocal3),
+                    code.Make(code.OpPop),
+                    code.Make(code.OpGetLocal, 4),
+                    code.Make(code.OpReturnValue),
+                },
+            },
+            expectedInstructions: []code.Instructions{
+                code.Make(code.OpConstant, 0),
+                code.Make(code.OpPop),
+            },
+        },
+        {
+            input:             "fn(n) { if (n < 10) { n + 1 } else { n } }",
+            expectedConstants: []interface{}{
+                10,
+                1,
+                []code.Instructions{
+                    code.Make(code.OpGetLocal0),
+                    code.Make(code.OpConstant, 0),
+                    code.Make(code.OpLessThanJump, 14),
+                    code.Make(code.OpGetLocal0),
+                    code.Make(code.OpAddConst, 1),
+                    code.Make(code.OpJump, 15),
+                    code.Make(code.OpGetLocal0),
+                    code.Make(code.OpReturnValue),
+                },
+            },
+            expectedInstructions: []code.Instructions{
+                code.Make(code.OpConstant, 2),
+                code.Make(code.OpPop),
+            },
+        },
+        {
+            input:             "fn(f) { f() + f(1) }",
+            expectedConstants: []interface{}{
+                1,
+                []code.Instructions{
+                    code.Make(code.OpGetLocal0),
+                    code.Make(code.OpCall0),
+                    code.Make(code.OpGetLocal0),
+                    code.Make(code.OpConstant, 0),
+                    code.Make(code.OpCall1),
+                    code.Make(code.OpAdd),
+                    code.Make(code.OpReturnValue),
+                },
+            },
+            expectedInstructions: []code.Instructions{
+                code.Make(code.OpConstant, 1),
+                code.Make(code.OpPop),
+            },
+        },
+        {
+            // the jump lands on the OpAdd, so the constant before it stays
+            input:             "fn(c) { 1 + if (c) { 2 } else { 3 } }",
+            expectedConstants: []interface{}{
+                1,
+                2,
+                3,
+                []code.Instructions{
+                    code.Make(code.OpConstant, 0),
+                    code.Make(code.OpGetLocal0),
+                    code.Make(code.OpJumpNotTruthy, 13),
+                    code.Make(code.OpConstant, 1),
+                    code.Make(code.OpJump, 16),
+                    code.Make(code.OpConstant, 2),
+                    code.Make(code.OpAdd),
+                    code.Make(code.OpReturnValue),
+                },
+            },
+            expectedInstructions: []code.Instructions{
+                code.Make(code.OpConstant, 3),
+                code.Make(code.OpPop),
+            },
+        },
+    }
+
+    runCompilerTestsWith(t, tests, Optimizations{Superinstructions: true})
+}
+
+// the names the lets in dropped code define stay defined, as they are
+// without the optimizations
+func TestDroppedLetsAreDefined(t *testing.T) {
+    inputs := []string{
+        "let f = fn(a) { if (1 > 2) { let x = 1; }; if (a) { return 7; }; x }",
+        "let f = fn(a) { if (true) { 1 } else { let x = if (a) { let y = 2; y }; }; y }",
+        "let f = fn(a) { if (a) { return 7; let x = 1; }; x }",
+        "if (false) { let x = 1; }; x",
+    }
+
+    optimizations := []Optimizations{
+        {ConstantFolding: true},
+        {DeadCode: true},
+        AllOptimizations(),
+    }
+
+    for _, input := range inputs {
+        for _, o := range optimizations {
+            compiler := New()
+            compiler.SetOptimizations(o)
+            err := compiler.Compile(parse(input))
+            if err != nil {
+                t.Errorf("%s with %+v: compiler error: %s", input, o, err)
+            }
+        }
+    }
+}
+
+// the tests spell out the unoptimized instructions, see
+// runOptimizedCompilerTests for the optimizations
 func runCompilerTests(t *testing.T, tests []compilerTestCase) {
     t.Helper()
 
+    runCompilerTestsWith(t, tests, Optimizations{})
+}
+
+func runOptimizedCompilerTests(t *testing.T, tests []compilerTestCase) {
+    t.Helper()
+
+    runCompilerTestsWith(t, tests, AllOptimizations())
+}
+
+func runCompilerTestsWith(t *testing.T, tests []compilerTestCase, o Optimizations) {
+    t.Helper()
+
     for _, tt := range tests {
         program := parse(tt.input)
 
         compiler := New()
+        compiler.SetOptimizations(o)
         err := compiler.Compile(program)
         if err != nil {
             t.Fatalf("compiler error: %s", err)
@@ -845,6 +1468,12 @@ func testConstants(
                 return fmt.Errorf("constant %d - testIntegerObject failed: %s",
                     i, err)
             }
+        case *object.FieldKey:
+            key, ok := actual[i].(*object.FieldKey)
+            if !ok || key.Name != constant.Name {
+                return fmt.Errorf("constant %d - wrong field key. want=%q, got=%+v",
+                    i, constant.Name, actual[i])
+            }
         case []code.Instructions:
             fn, ok := actual[i].(*object.CompiledFunction)
             if !ok {
@@ -0,0 +1,117 @@
+package compiler
+
+import (
+    "fmt"
+    "myMonkey/ast"
+    "myMonkey/object"
+)
+
+// Warnings returns what the compiler found worth pointing out without
+// failing, such as the code it dropped as unreachable
+func (c *Compiler) Warnings() []string {
+    return c.warnings
+}
+
+func (c *Compiler) warn(format string, a ...interface{}) {
+    c.warnings = append(c.warnings, fmt.Sprintf(format, a...))
+}
+
+// compiles statements up to the first one that always returns, the rest
+// can never run
+// defineDropped defines the names that the lets in code the compiler drops
+// would have defined, so that code referring to them still compiles, as it
+// does without the optimizations. Functions define theirs in their own
+// scope and are left alone.
+func (c *Compiler) defineDropped(node ast.Node) {
+    switch node := node.(type) {
+    case *ast.BlockStatement:
+        if node == nil {
+            return
+        }
+        for _, s := range node.Statements {
+            c.defineDropped(s)
+        }
+    case *ast.LetStatement:
+        c.defineDropped(node.Value)
+        c.symbolTable.Define(node.Name.Value)
+    case *ast.ExpressionStatement:
+        c.defineDropped(node.Expression)
+    case *ast.ReturnStatement:
+        c.defineDropped(node.ReturnValue)
+    case *ast.IfExpression:
+        c.defineDropped(node.Condition)
+        c.defineDropped(node.Consequence)
+        c.defineDropped(node.Alternative)
+    case *ast.PrefixOpExpression:
+        c.defineDropped(node.Right)
+    case *ast.InfixExpression:
+        c.defineDropped(node.Left)
+        c.defineDropped(node.Right)
+    case *ast.CallExpression:
+        c.defineDropped(node.Function)
+        for _, arg := range node.Arguments {
+            c.defineDropped(arg)
+        }
+    }
+}
+
+func (c *Compiler) compileStatements(statements []ast.Statement) error {
+    for i, s := range statements {
+        // asked before compiling s, which may rebind names its conditions use
+        returns := c.optimizations.DeadCode && c.alwaysReturns(s)
+
+        err := c.Compile(s)
+        if err != nil {
+            return err
+        }
+
+        if returns && i + 1 < len(statements) {
+            c.warn("unreachable code after return: %s", statements[i + 1].String())
+            for _, dropped := range statements[i + 1:] {
+                c.defineDropped(dropped)
+            }
+            break
+        }
+    }
+
+    return nil
+}
+
+// a return, or an if all of whose branches that can be taken return
+func (c *Compiler) alwaysReturns(s ast.Statement) bool {
+    switch s := s.(type) {
+    case *ast.ReturnStatement:
+        return true
+
+    case *ast.ExpressionStatement:
+        ifExp, ok := s.Expression.(*ast.IfExpression)
+        if !ok {
+            return false
+        }
+
+        if condition, ok := c.constantValue(ifExp.Condition); ok {
+            if condition == object.FALSE {
+                return c.blockAlwaysReturns(ifExp.Alternative)
+            }
+            return c.blockAlwaysReturns(ifExp.Consequence)
+        }
+
+        return c.blockAlwaysReturns(ifExp.Consequence) && c.blockAlwaysReturns(ifExp.Alternative)
+    }
+
+    return false
+}
+
+func (c *Compiler) blockAlwaysReturns(block *ast.BlockStatement) bool {
+    if block == nil {
+        return false
+    }
+
+    for _, s := range block.Statements {
+        if c.alwaysReturns(s) {
+            return true
+        }
+    }
+
+    return false
+}
@@ -0,0 +1,216 @@
+package compiler
+
+import (
+    "myMonkey/ast"
+    "myMonkey/code"
+    "myMonkey/object"
+)
+
+// Optimizations selects the optimization passes the compiler runs. New
+// compilers run all of them.
+type Optimizations struct {
+    // computes integer, string and boolean expressions at compile time,
+    // including names bound to such values and constant if conditions
+    ConstantFolding   bool
+
+    // rewrites wasteful instruction sequences, see peephole
+    Peephole          bool
+
+    // drops the statements after a return and the branches of ifs that are
+    // never taken, with a warning for each
+    DeadCode          bool
+
+    // emits OpTailCall for calls in tail position, see markTailCalls
+    TailCalls         bool
+
+    // lowers common sequences into specialised opcodes, see superinstructions
+    Superinstructions bool
+}
+
+func AllOptimizations() Optimizations {
+    return Optimizations{
+        ConstantFolding:   true,
+        Peephole:          true,
+        DeadCode:          true,
+        TailCalls:         true,
+        Superinstructions: true,
+    }
+}
+
+func (c *Compiler) SetOptimizations(o Optimizations) {
+    c.optimizations = o
+}
+
+// constantValue returns the value of node if it is known at compile time.
+// Only values the VM would compute the same way are folded, division by
+// zero is left alone as it has to fail at runtime.
+func (c *Compiler) constantValue(node ast.Expression) (object.Object, bool) {
+    switch node := node.(type) {
+    case *ast.IntegerLiteral:
+        return object.NewInteger(node.Value), true
+
+    case *ast.StringLiteral:
+        return &object.String{Value: node.Value}, true
+
+    case *ast.Boolean:
+        return nativeBoolToBooleanObject(node.Value), true
+
+    case *ast.Identifier:
+        return c.symbolTable.ResolveConstant(node.Value)
+
+    case *ast.PrefixOpExpression:
+        right, ok := c.constantValue(node.Right)
+        if !ok {
+            return nil, false
+        }
+
+        switch node.Operator {
+        case "!":
+            // everything but false is truthy, null is never a constant
+            return nativeBoolToBooleanObject(right == object.FALSE), true
+        case "-":
+            if integer, ok := right.(*object.Integer); ok {
+                return object.NewInteger(-integer.Value), true
+            }
+        }
+
+    case *ast.InfixExpression:
+        left, ok := c.constantValue(node.Left)
+        if !ok {
+            return nil, false
+        }
+
+        right, ok := c.constantValue(node.Right)
+        if !ok {
+            return nil, false
+        }
+
+        return foldInfix(node.Operator, left, right)
+    }
+
+    return nil, false
+}
+
+func foldInfix(operator string, left, right object.Object) (object.Object, bool) {
+    switch left := left.(type) {
+    case *object.Integer:
+        right, ok := right.(*object.Integer)
+        if !ok {
+            return nil, false
+        }
+
+        switch operator {
+        case "+":
+            return object.NewInteger(left.Value + right.Value), true
+        case "-":
+            return object.NewInteger(left.Value - right.Value), true
+        case "*":
+            return object.NewInteger(left.Value * right.Value), true
+        case "/":
+            if right.Value != 0 {
+                return object.NewInteger(left.Value / right.Value), true
+            }
+        case "<":
+            return nativeBoolToBooleanObject(left.Value < right.Value), true
+        case ">":
+            return nativeBoolToBooleanObject(left.Value > right.Value), true
+        case "==":
+            return nativeBoolToBooleanObject(left.Value == right.Value), true
+        case "!=":
+            return nativeBoolToBooleanObject(left.Value != right.Value), true
+        }
+
+    case *object.String:
+        right, ok := right.(*object.String)
+        if !ok {
+            return nil, false
+        }
+
+        switch operator {
+        case "+":
+            return &object.String{Value: left.Value + right.Value}, true
+        case "==":
+            return nativeBoolToBooleanObject(left.Value == right.Value), true
+        case "!=":
+            return nativeBoolToBooleanObject(left.Value != right.Value), true
+        }
+
+    case *object.Boolean:
+        right, ok := right.(*object.Boolean)
+        if !ok {
+            return nil, false
+        }
+
+        switch operator {
+        case "==":
+            return nativeBoolToBooleanObject(left == right), true
+        case "!=":
+            return nativeBoolToBooleanObject(left != right), true
+        }
+    }
+
+    return nil, false
+}
+
+func (c *Compiler) emitConstant(value object.Object) {
+    switch value {
+    case object.TRUE:
+        c.emit(code.OpTrue)
+    case object.FALSE:
+        c.emit(code.OpFalse)
+    default:
+        c.emit(code.OpConstant, c.addConstant(value))
+    }
+}
+
+// compiles only the branch of an if that its constant condition selects
+func (c *Compiler) compileConstantIf(node *ast.IfExpression, condition object.Object) error {
+    branch, dropped := node.Consequence, node.Alternative
+    if condition == object.FALSE {
+        branch, dropped = node.Alternative, node.Consequence
+    }
+
+    if dropped != nil {
+        c.warn("unreachable branch: condition %s is always %t",
+            node.Condition.String(), condition != object.FALSE)
+        c.defineDropped(dropped)
+    }
+
+    if branch == nil {
+        c.emit(code.OpNull)
+        return nil
+    }
+
+    return c.compileBranch(branch)
+}
+
+// compiles a branch of an if so that it leaves its value on the stack,
+// null when it ends in a statement that yields none, such as a let. A
+// branch that returns leaves nothing.
+func (c *Compiler) compileBranch(branch *ast.BlockStatement) error {
+    start := len(c.currentInstructions())
+
+    err := c.Compile(branch)
+    if err != nil {
+        return err
+    }
+
+    switch {
+    case len(c.currentInstructions()) == start:
+        c.emit(code.OpNull)
+    case c.lastInstructionIs(code.OpPop):
+        c.removeLastPop()
+    case !c.lastInstructionIs(code.OpReturnValue):
+        c.emit(code.OpNull)
+    }
+
+    return nil
+}
+
+func nativeBoolToBooleanObject(b bool) *object.Boolean {
+    if b {
+        return object.TRUE
+    }
+
+    return object.FALSE
+}
@@ -0,0 +1,181 @@
+package compiler
+
+import "myMonkey/code"
+
+// an instruction as the peephole pass sees it. Jumps refer to the index of
+// the instruction they land on rather than to an offset, so that
+// instructions can be rewritten and dropped without breaking them.
+type peepholeInstruction struct {
+    op       code.Opcode
+    operands []int
+    target   int
+    removed  bool
+}
+
+// peephole rewrites wasteful patterns in the instructions of one scope:
+//
+//   OpTrue, OpJumpNotTruthy       => nothing, the jump is never taken
+//   OpFalse, OpJumpNotTruthy x    => OpJump x
+//   a jump to an OpJump           => a jump to where that one goes
+//   a jump to the next instruction, and instructions that nothing reaches
+//                                 => nothing
+//   OpNull, OpPop                 => nothing, and jumps to the pair skip it
+//
+// The last rule is left out of the main scope, as the last popped value is
+// what a program evaluates to.
+func peephole(ins code.Instructions, isMain bool) code.Instructions {
+    list := decodeInstructions(ins)
+
+    // the rules only ever shorten the list or jump chains, the cap merely
+    // guards against jump cycles, which the compiler does not produce
+    for pass := 0; pass <= len(list); pass++ {
+        changed := false
+        for i := range list {
+            if !list[i].removed && applyPeepholeRules(list, i, isMain) {
+                changed = true
+            }
+        }
+
+        if !changed {
+            break
+        }
+    }
+
+    return encodeInstructions(list)
+}
+
+func applyPeepholeRules(list []peepholeInstruction, i int, isMain bool) bool {
+    in := &list[i]
+    j  := nextInstruction(list, i)
+
+    switch {
+    case in.op == code.OpTrue && isOp(list, j, code.OpJumpNotTruthy) && !isJumpTarget(list, j):
+        in.removed      = true
+        list[j].removed = true
+        return true
+
+    case in.op == code.OpFalse && isOp(list, j, code.OpJumpNotTruthy) && !isJumpTarget(list, j):
+        in.removed = true
+        list[j].op = code.OpJump
+        return true
+
+    case isJump(in.op) && isOp(list, resolveTarget(list, in.target), code.OpJump) &&
+        resolveTarget(list, in.target) != i:
+        in.target = list[resolveTarget(list, in.target)].target
+        return true
+
+    case in.op == code.OpJump && resolveTarget(list, in.target) == j:
+        in.removed = true
+        return true
+
+    case in.op == code.OpJump || in.op == code.OpReturnValue || in.op == code.OpReturn:
+        changed := false
+        for ; j < len(list) && !isJumpTarget(list, j); j = nextInstruction(list, j) {
+            list[j].removed = true
+            changed = true
+        }
+        return changed
+
+    case isMain:
+        return false
+
+    case in.op == code.OpNull && isOp(list, j, code.OpPop) && !isJumpTarget(list, j):
+        in.removed      = true
+        list[j].removed = true
+        return true
+
+    case isJump(in.op):
+        t := resolveTarget(list, in.target)
+        u := nextInstruction(list, t)
+        if isOp(list, t, code.OpNull) && isOp(list, u, code.OpPop) {
+            in.target = nextInstruction(list, u)
+            return true
+        }
+    }
+
+    return false
+}
+
+func isJump(op code.Opcode) bool {
+    return op == code.OpJump || op == code.OpJumpNotTruthy || op == code.OpLessThanJump
+}
+
+func isOp(list []peepholeInstruction, i int, op code.Opcode) bool {
+    return i < len(list) && list[i].op == op
+}
+
+// the index of the first instruction after i that is still there
+func nextInstruction(list []peepholeInstruction, i int) int {
+    return resolveTarget(list, i + 1)
+}
+
+// a jump to a removed instruction lands on the next one still there
+func resolveTarget(list []peepholeInstruction, i int) int {
+    for i < len(list) && list[i].removed {
+        i++
+    }
+
+    return i
+}
+
+func isJumpTarget(list []peepholeInstruction, i int) bool {
+    for _, in := range list {
+        if !in.removed && isJump(in.op) && resolveTarget(list, in.target) == i {
+            return true
+        }
+    }
+
+    return false
+}
+
+func decodeInstructions(ins code.Instructions) []peepholeInstruction {
+    list    := []peepholeInstruction{}
+    indices := map[int]int{}
+
+    for offset := 0; offset < len(ins); {
+        def, _ := code.Lookup(ins[offset])
+        operands, read := code.ReadOperands(def, ins[offset + 1:])
+
+        indices[offset] = len(list)
+        list = append(list, peepholeInstruction{op: code.Opcode(ins[offset]), operands: operands})
+
+        offset += 1 + read
+    }
+    indices[len(ins)] = len(list)
+
+    for i := range list {
+        if isJump(list[i].op) {
+            list[i].target = indices[list[i].operands[0]]
+        }
+    }
+
+    return list
+}
+
+func encodeInstructions(list []peepholeInstruction) code.Instructions {
+    // removed instructions take no space, so they share the offset of the
+    // next instruction that is still there, just like resolveTarget
+    offsets := make([]int, len(list) + 1)
+    offset  := 0
+    for i, in := range list {
+        offsets[i] = offset
+        if !in.removed {
+            offset += len(code.Make(in.op, in.operands...))
+        }
+    }
+    offsets[len(list)] = offset
+
+    out := code.Instructions{}
+    for _, in := range list {
+        if in.removed {
+            continue
+        }
+
+        if isJump(in.op) {
+            in.operands = []int{offsets[in.target]}
+        }
+        out = append(out, code.Make(in.op, in.operands...)...)
+    }
+
+    return out
+}
@@ -0,0 +1,148 @@
+package compiler
+
+import (
+    "testing"
+    "myMonkey/object"
+)
+
+func TestPeephole(t *testing.T) {
+    tests := []struct {
+        input  string
+        before string
+        after  string
+    }{
+        {
+            // checks the instructions of the function, the last constant
+            input: "fn(x) { if (x) { 1 }; 2 }",
+            before: `0000 OpGetLocal 0
+0002 OpJumpNotTruthy 11
+0005 OpConstant 0
+0008 OpJump 12
+0011 OpNull
+0012 OpPop
+0013 OpConstant 1
+0016 OpReturnValue
+`,
+            after: `0000 OpGetLocal 0
+0002 OpJumpNotTruthy 9
+0005 OpConstant 0
+0008 OpPop
+0009 OpConstant 1
+0012 OpReturnValue
+`,
+        },
+        {
+            input: "if (true) { 10 }; 3333;",
+            before: `0000 OpTrue
+0001 OpJumpNotTruthy 10
+0004 OpConstant 0
+0007 OpJump 11
+0010 OpNull
+0011 OpPop
+0012 OpConstant 1
+0015 OpPop
+`,
+            after: `0000 OpConstant 0
+0003 OpPop
+0004 OpConstant 1
+0007 OpPop
+`,
+        },
+        {
+            input: "if (false) { 10 } else { 20 }",
+            before: `0000 OpFalse
+0001 OpJumpNotTruthy 10
+0004 OpConstant 0
+0007 OpJump 13
+0010 OpConstant 1
+0013 OpPop
+`,
+            after: `0000 OpConstant 1
+0003 OpPop
+`,
+        },
+        {
+            // the main scope keeps its OpNull, OpPop: the null is what the
+            // program evaluates to
+            input: "let x = 1; if (x) { 10 }",
+            before: `0000 OpConstant 0
+0003 OpSetGlobal 0
+0006 OpGetGlobal 0
+0009 OpJumpNotTruthy 18
+0012 OpConstant 1
+0015 OpJump 19
+0018 OpNull
+0019 OpPop
+`,
+            after: `0000 OpConstant 0
+0003 OpSetGlobal 0
+0006 OpGetGlobal 0
+0009 OpJumpNotTruthy 18
+0012 OpConstant 1
+0015 OpJump 19
+0018 OpNull
+0019 OpPop
+`,
+        },
+        {
+            // the inner if jumps to the OpJump at the end of the outer one,
+            // the chain is threaded straight to the OpPop
+            input: "fn(x, y) { if (x) { if (y) { 1 } else { 2 } } else { 3 }; 4 }",
+            before: `0000 OpGetLocal 0
+0002 OpJumpNotTruthy 22
+0005 OpGetLocal 1
+0007 OpJumpNotTruthy 16
+0010 OpConstant 0
+0013 OpJump 19
+0016 OpConstant 1
+0019 OpJump 25
+0022 OpConstant 2
+0025 OpPop
+0026 OpConstant 3
+0029 OpReturnValue
+`,
+            after: `0000 OpGetLocal 0
+0002 OpJumpNotTruthy 22
+0005 OpGetLocal 1
+0007 OpJumpNotTruthy 16
+0010 OpConstant 0
+0013 OpJump 25
+0016 OpConstant 1
+0019 OpJump 25
+0022 OpConstant 2
+0025 OpPop
+0026 OpConstant 3
+0029 OpReturnValue
+`,
+        },
+    }
+
+    for _, tt := range tests {
+        for _, run := range []struct {
+            optimizations Optimizations
+            expected      string
+        }{
+            {Optimizations{}, tt.before},
+            {Optimizations{Peephole: true}, tt.after},
+        } {
+            compiler := New()
+            compiler.SetOptimizations(run.optimizations)
+            err := compiler.Compile(parse(tt.input))
+            if err != nil {
+                t.Fatalf("compiler error: %s", err)
+            }
+
+            bytecode := compiler.Bytecode()
+            instructions := bytecode.Instructions
+            last := bytecode.Constants[len(bytecode.Constants) - 1]
+            if fn, ok := last.(*object.CompiledFunction); ok {
+                instructions = fn.Instructions
+            }
+
+            if instructions.String() != run.expected {
+                t.Errorf("%s (%+v): wrong instructions.\nwant=%q\ngot =%q",
+                    tt.input, run.optimizations, run.expected, instructions.String())
+            }
+        }
+    }
+}
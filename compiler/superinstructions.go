@@ -0,0 +1,60 @@
+package compiler
+
+import "myMonkey/code"
+
+var getLocalOps = []code.Opcode{
+    code.OpGetLocal0,
+    code.OpGetLocal1,
+    code.OpGetLocal2,
+    code.OpGetLocal3,
+}
+
+// superinstructions lowers common sequences into the specialised opcodes
+// the vm dispatches once instead of twice:
+//
+//   OpGetLocal 0-3                => OpGetLocal0-3
+//   OpConstant x, OpAdd           => OpAddConst x
+//   OpLessThan, OpJumpNotTruthy x => OpLessThanJump x
+//   OpCall 0-1                    => OpCall0-1
+//
+// A pair is left alone when a jump lands on its second instruction. It runs
+// after the peephole pass, whose rules do not know these opcodes.
+func superinstructions(ins code.Instructions) code.Instructions {
+    list    := decodeInstructions(ins)
+    targets := map[int]bool{}
+    for _, in := range list {
+        if isJump(in.op) {
+            targets[in.target] = true
+        }
+    }
+
+    for i := range list {
+        in := &list[i]
+        j  := i + 1
+
+        switch {
+        case in.op == code.OpGetLocal && in.operands[0] < len(getLocalOps):
+            in.op       = getLocalOps[in.operands[0]]
+            in.operands = nil
+
+        case in.op == code.OpConstant && isOp(list, j, code.OpAdd) && !targets[j]:
+            in.op           = code.OpAddConst
+            list[j].removed = true
+
+        case in.op == code.OpLessThan && isOp(list, j, code.OpJumpNotTruthy) && !targets[j]:
+            in.op           = code.OpLessThanJump
+            in.target       = list[j].target
+            list[j].removed = true
+
+        case in.op == code.OpCall && in.operands[0] == 0:
+            in.op       = code.OpCall0
+            in.operands = nil
+
+        case in.op == code.OpCall && in.operands[0] == 1:
+            in.op       = code.OpCall1
+            in.operands = nil
+        }
+    }
+
+    return encodeInstructions(list)
+}
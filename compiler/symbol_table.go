@@ -1,5 +1,7 @@
 package compiler
 
+import "myMonkey/object"
+
 type SymbolScope string
 
 const (
@@ -22,11 +24,14 @@ type SymbolTable struct {
 
     store          map[string]Symbol
     numDefinitions int
+
+    // values of the symbols bound to constants, for constant propagation
+    constants      map[string]object.Object
 }
 
 func NewSymbolTable() *SymbolTable {
     s := make(map[string]Symbol)
-    return &SymbolTable{store: s}
+    return &SymbolTable{store: s, constants: make(map[string]object.Object)}
 }
 
 func NewEnclosedSymbolTable(outer *SymbolTable) *SymbolTable {
@@ -35,7 +40,16 @@ func NewEnclosedSymbolTable(outer *SymbolTable) *SymbolTable {
     return s
 }
 
+// defining a name again in the same table rebinds the slot it already has,
+// so that code compiled in between, and a branch that does not run, see
+// the binding the evaluator would
 func (s *SymbolTable) Define(name string) Symbol {
+    delete(s.constants, name)
+
+    if symbol, ok := s.store[name]; ok && symbol.Scope != BuiltinScope {
+        return symbol
+    }
+
     symbol := Symbol {
         Name:   name,
         Index:  s.numDefinitions,
@@ -52,6 +66,19 @@ func (s *SymbolTable) Define(name string) Symbol {
     return symbol
 }
 
+// SetConstant records that name is bound to a value known at compile time
+func (s *SymbolTable) SetConstant(name string, value object.Object) {
+    s.constants[name] = value
+}
+
+// ResolveConstant returns the value name is bound to, if that is known at
+// compile time. Only names of this table qualify, as a function may run
+// after the enclosing scope has bound its names to something else.
+func (s *SymbolTable) ResolveConstant(name string) (object.Object, bool) {
+    value, ok := s.constants[name]
+    return value, ok
+}
+
 func (s *SymbolTable) Resolve(name string) (Symbol, bool) {
     obj, ok := s.store[name]
     if !ok && s.Outer != nil {
@@ -0,0 +1,68 @@
+package compiler
+
+import "myMonkey/ast"
+
+// markTailCalls finds the calls in the body of a function whose value the
+// function returns as is: the value of a return, and of the last statement
+// of the body, looking into the branches of ifs. Nested functions are left
+// to their own turn.
+func (c *Compiler) markTailCalls(body *ast.BlockStatement) {
+    c.markTailBlock(body)
+}
+
+// marks the last expression of block, and every return in it
+func (c *Compiler) markTailBlock(block *ast.BlockStatement) {
+    if block == nil {
+        return
+    }
+
+    for i, s := range block.Statements {
+        switch s := s.(type) {
+        case *ast.ReturnStatement:
+            c.markTailExpression(s.ReturnValue)
+        case *ast.LetStatement:
+            c.markReturns(s.Value)
+        case *ast.ExpressionStatement:
+            if i == len(block.Statements) - 1 {
+                c.markTailExpression(s.Expression)
+            } else {
+                c.markReturns(s.Expression)
+            }
+        }
+    }
+}
+
+func (c *Compiler) markTailExpression(exp ast.Expression) {
+    switch exp := exp.(type) {
+    case *ast.CallExpression:
+        c.tailCalls[exp] = true
+    case *ast.IfExpression:
+        c.markTailBlock(exp.Consequence)
+        c.markTailBlock(exp.Alternative)
+    }
+}
+
+// an if whose value is not returned may still hold returns
+func (c *Compiler) markReturns(exp ast.Expression) {
+    ifExp, ok := exp.(*ast.IfExpression)
+    if !ok {
+        return
+    }
+
+    for _, block := range []*ast.BlockStatement{ifExp.Consequence, ifExp.Alternative} {
+        if block == nil {
+            continue
+        }
+
+        for _, s := range block.Statements {
+            switch s := s.(type) {
+            case *ast.ReturnStatement:
+                c.markTailExpression(s.ReturnValue)
+            case *ast.LetStatement:
+                c.markReturns(s.Value)
+            case *ast.ExpressionStatement:
+                c.markReturns(s.Expression)
+            }
+        }
+    }
+}
@@ -5,10 +5,76 @@ import (
 )
 
 var Builtins = map[string]*object.Builtin {
-    "len":     object.GetBuiltinByName("len"),
-    "first":   object.GetBuiltinByName("first"),
-    "last":    object.GetBuiltinByName("last"),
-    "rest":    object.GetBuiltinByName("rest"),
-    "push":    object.GetBuiltinByName("push"),
-    "puts":    object.GetBuiltinByName("puts"),
+    "len":             object.GetBuiltinByName("len"),
+    "first":           object.GetBuiltinByName("first"),
+    "last":            object.GetBuiltinByName("last"),
+    "rest":            object.GetBuiltinByName("rest"),
+    "push":            object.GetBuiltinByName("push"),
+    "puts":            object.GetBuiltinByName("puts"),
+    "type":            object.GetBuiltinByName("type"),
+    "is_int":          object.GetBuiltinByName("is_int"),
+    "is_string":       object.GetBuiltinByName("is_string"),
+    "is_array":        object.GetBuiltinByName("is_array"),
+    "is_hash":         object.GetBuiltinByName("is_hash"),
+    "is_fn":           object.GetBuiltinByName("is_fn"),
+    "int":             object.GetBuiltinByName("int"),
+    "str":             object.GetBuiltinByName("str"),
+    "bool":            object.GetBuiltinByName("bool"),
+    "abs":             object.GetBuiltinByName("abs"),
+    "min":             object.GetBuiltinByName("min"),
+    "max":             object.GetBuiltinByName("max"),
+    "pow":             object.GetBuiltinByName("pow"),
+    "sqrt":            object.GetBuiltinByName("sqrt"),
+    "random":          object.GetBuiltinByName("random"),
+    "read_file":       object.GetBuiltinByName("read_file"),
+    "write_file":      object.GetBuiltinByName("write_file"),
+    "list_dir":        object.GetBuiltinByName("list_dir"),
+    "exists":          object.GetBuiltinByName("exists"),
+    "json_encode":     object.GetBuiltinByName("json_encode"),
+    "json_decode":     object.GetBuiltinByName("json_decode"),
+    "regex":           object.GetBuiltinByName("regex"),
+    "match":           object.GetBuiltinByName("match"),
+    "find_all":        object.GetBuiltinByName("find_all"),
+    "captures":        object.GetBuiltinByName("captures"),
+    "replace":         object.GetBuiltinByName("replace"),
+    "split":           object.GetBuiltinByName("split"),
+    "now":             object.GetBuiltinByName("now"),
+    "sleep":           object.GetBuiltinByName("sleep"),
+    "format_time":     object.GetBuiltinByName("format_time"),
+    "parse_time":      object.GetBuiltinByName("parse_time"),
+    "duration":        object.GetBuiltinByName("duration"),
+    "format_duration": object.GetBuiltinByName("format_duration"),
+    "eputs":           object.GetBuiltinByName("eputs"),
+    "print":           object.GetBuiltinByName("print"),
+    "read_line":       object.GetBuiltinByName("read_line"),
+    "set":             object.GetBuiltinByName("set"),
+    "is_set":          object.GetBuiltinByName("is_set"),
+    "add":             object.GetBuiltinByName("add"),
+    "remove":          object.GetBuiltinByName("remove"),
+    "contains":        object.GetBuiltinByName("contains"),
+    "union":           object.GetBuiltinByName("union"),
+    "intersection":    object.GetBuiltinByName("intersection"),
+    "difference":      object.GetBuiltinByName("difference"),
+    "to_array":        object.GetBuiltinByName("to_array"),
+    "bytes":           object.GetBuiltinByName("bytes"),
+    "is_bytes":        object.GetBuiltinByName("is_bytes"),
+    "hex_encode":      object.GetBuiltinByName("hex_encode"),
+    "hex_decode":      object.GetBuiltinByName("hex_decode"),
+    "base64_encode":   object.GetBuiltinByName("base64_encode"),
+    "base64_decode":   object.GetBuiltinByName("base64_decode"),
+    "sha256":          object.GetBuiltinByName("sha256"),
+    "sha1":            object.GetBuiltinByName("sha1"),
+    "md5":             object.GetBuiltinByName("md5"),
+    "crc32":           object.GetBuiltinByName("crc32"),
+    "hmac":            object.GetBuiltinByName("hmac"),
+    "args":            object.GetBuiltinByName("args"),
+    "env":             object.GetBuiltinByName("env"),
+    "exit":            object.GetBuiltinByName("exit"),
+    "exec":            object.GetBuiltinByName("exec"),
+    "http_get":        object.GetBuiltinByName("http_get"),
+    "http_post":       object.GetBuiltinByName("http_post"),
+    "http_request":    object.GetBuiltinByName("http_request"),
+    "serve":           object.GetBuiltinByName("serve"),
+    "csv_parse":       object.GetBuiltinByName("csv_parse"),
+    "csv_format":      object.GetBuiltinByName("csv_format"),
 }
@@ -1,15 +1,16 @@
 package evaluator
 
 import (
+    "bytes"
     "fmt"
     "myMonkey/ast"
     "myMonkey/object"
 )
 
 var (
-    NULL  = &object.Null{}
-    TRUE  = &object.Boolean{Value: true}
-    FALSE = &object.Boolean{Value: false}
+    NULL  = object.NULL
+    TRUE  = object.TRUE
+    FALSE = object.FALSE
 )
 
 func Eval(node ast.Node, env *object.Environment) object.Object {
@@ -41,7 +42,7 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
         return evalIdentifier(node, env)
 
     case *ast.IntegerLiteral:
-        return &object.Integer{Value: node.Value}
+        return object.NewInteger(node.Value)
 
     case *ast.StringLiteral:
         return &object.String{Value: node.Value}
@@ -91,6 +92,34 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 
         return evalIndexExpression(left, index)
 
+    case *ast.SliceExpression:
+        left := Eval(node.Left, env)
+        if isError(left) {
+            return left
+        }
+
+        bounds := []object.Object{NULL, NULL}
+        for i, bound := range []ast.Expression{node.Low, node.High} {
+            if bound == nil {
+                continue
+            }
+
+            bounds[i] = Eval(bound, env)
+            if isError(bounds[i]) {
+                return bounds[i]
+            }
+        }
+
+        return evalSliceExpression(left, bounds[0], bounds[1])
+
+    case *ast.FieldExpression:
+        left := Eval(node.Left, env)
+        if isError(left) {
+            return left
+        }
+
+        return evalFieldExpression(left, node.Field.Value)
+
     case *ast.IfExpression:
         return evalIfExpression(node, env)
 
@@ -113,7 +142,20 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
             return args[0]
         }
 
-        return applyFunction(function, args)
+        return applyFunction(function, args, env.Host())
+
+    case *ast.MethodCallExpression:
+        receiver := Eval(node.Receiver, env)
+        if isError(receiver) {
+            return receiver
+        }
+
+        args := evalExpressions(node.Arguments, env)
+        if len(args) == 1 && isError(args[0]) {
+            return args[0]
+        }
+
+        return evalMethodCall(receiver, node.Method.Value, args, env)
     }
 
     return nil
@@ -130,6 +172,8 @@ func evalProgram(program *ast.Program, env *object.Environment) object.Object {
             return result.Value
         case *object.Error:
             return result
+        case *object.Exit:
+            return result
         }
     }
 
@@ -145,7 +189,8 @@ func evalBlockStatement(block *ast.BlockStatement, env *object.Environment) obje
         if result != nil {
             rt := result.Type()
             if rt == object.RETURN_VALUE_OBJ ||
-               rt == object.ERROR_OBJ {
+               rt == object.ERROR_OBJ ||
+               rt == object.EXIT_OBJ {
                 return result
             }
         }
@@ -163,7 +208,7 @@ func evalIdentifier(node *ast.Identifier, env *object.Environment) object.Object
         return builtin
     }
 
-    return newError("identifier not found: " + node.Value)
+    return newError("identifier not found: %s", node.Value)
 }
 
 func evalHashLiteral(node *ast.HashLiteral, env *object.Environment) object.Object {
@@ -216,6 +261,10 @@ func evalInfixExpression(operator string,
         return evalStringInfixExpression(operator, left, right)
     case lType == object.BOOLEAN_OBJ && rType == object.BOOLEAN_OBJ:
         return evalBooleanInfixExpression(operator, left, right)
+    case lType == object.BYTES_OBJ && rType == object.BYTES_OBJ:
+        return evalBytesInfixExpression(operator, left, right)
+    case lType == object.SET_OBJ && rType == object.SET_OBJ:
+        return evalSetInfixExpression(operator, left, right)
     case lType != rType:
         return newError("type mismatch: %s %s %s",
             lType, operator, rType)
@@ -230,8 +279,9 @@ func evalIndexExpression(left, index object.Object) object.Object {
     iType := index.Type()
 
     switch {
-    case lType == object.ARRAY_OBJ && iType == object.INTEGER_OBJ:
-        return evalArrayIndexExpression(left, index)
+    case (lType == object.ARRAY_OBJ || lType == object.STRING_OBJ || lType == object.BYTES_OBJ) &&
+        iType == object.INTEGER_OBJ:
+        return object.IndexSequence(left, index.(*object.Integer).Value)
     case lType == object.HASH_OBJ:
         return evalHashIndexExpression(left, index)
     default:
@@ -239,16 +289,13 @@ func evalIndexExpression(left, index object.Object) object.Object {
     }
 }
 
-func evalArrayIndexExpression(left, index object.Object) object.Object {
-    array := left.(*object.Array)
-    idx   := index.(*object.Integer).Value
-    max   := int64(len(array.Elements) - 1)
-
-    if idx < 0 || idx > max {
-        return NULL
+func evalSliceExpression(left, low, high object.Object) object.Object {
+    result, err := object.Slice(left, low, high)
+    if err != nil {
+        return newError("%s", err)
     }
 
-    return array.Elements[idx]
+    return result
 }
 
 func evalHashIndexExpression(left, index object.Object) object.Object {
@@ -267,6 +314,23 @@ func evalHashIndexExpression(left, index object.Object) object.Object {
     return pair.Value
 }
 
+// unlike indexing, reading a field that isn't there is an error
+func evalFieldExpression(left object.Object, field string) object.Object {
+    hashObject, ok := left.(*object.Hash)
+    if !ok {
+        return newError("field access not supported: %s", left.Type())
+    }
+
+    key := &object.String{Value: field}
+
+    pair, ok := hashObject.Pairs[key.HashKey()]
+    if !ok {
+        return newError("unknown field: %s", field)
+    }
+
+    return pair.Value
+}
+
 func evalBangOperatorExpression(right object.Object) object.Object {
     switch right {
     case TRUE:
@@ -286,7 +350,7 @@ func evalMinusPrefixOperatorExpression(right object.Object) object.Object {
     }
 
     value := right.(*object.Integer).Value
-    return &object.Integer{Value: -value}
+    return object.NewInteger(-value)
 }
 
 func evalIntegerInfixExpression(operator string,
@@ -296,13 +360,13 @@ func evalIntegerInfixExpression(operator string,
     rightVal := right.(*object.Integer).Value
     switch operator {
     case "+":
-        return &object.Integer{Value: leftVal + rightVal}
+        return object.NewInteger(leftVal + rightVal)
     case "-":
-        return &object.Integer{Value: leftVal - rightVal}
+        return object.NewInteger(leftVal - rightVal)
     case "*":
-        return &object.Integer{Value: leftVal * rightVal}
+        return object.NewInteger(leftVal * rightVal)
     case "/":
-        return &object.Integer{Value: leftVal / rightVal}
+        return object.NewInteger(leftVal / rightVal)
     case "<":
         return nativeBoolToBooleanObject(leftVal < rightVal)
     case ">":
@@ -326,6 +390,48 @@ func evalStringInfixExpression(operator string,
     switch operator {
     case "+":
         return &object.String{Value: leftVal + rightVal}
+    case "==":
+        return nativeBoolToBooleanObject(leftVal == rightVal)
+    case "!=":
+        return nativeBoolToBooleanObject(leftVal != rightVal)
+    default:
+        return newError("unknown operator: %s %s %s",
+            left.Type(), operator, right.Type())
+    }
+}
+
+// sets are equal when they hold the same elements
+func evalSetInfixExpression(operator string,
+    left, right object.Object) object.Object {
+
+    equal := left.(*object.Set).Equal(right.(*object.Set))
+
+    switch operator {
+    case "==":
+        return nativeBoolToBooleanObject(equal)
+    case "!=":
+        return nativeBoolToBooleanObject(!equal)
+    default:
+        return newError("unknown operator: %s %s %s",
+            left.Type(), operator, right.Type())
+    }
+}
+
+func evalBytesInfixExpression(operator string,
+    left, right object.Object) object.Object {
+
+    leftVal  := left.(*object.Bytes).Value
+    rightVal := right.(*object.Bytes).Value
+
+    switch operator {
+    case "+":
+        value := make([]byte, 0, len(leftVal) + len(rightVal))
+        value  = append(value, leftVal...)
+        return &object.Bytes{Value: append(value, rightVal...)}
+    case "==":
+        return nativeBoolToBooleanObject(bytes.Equal(leftVal, rightVal))
+    case "!=":
+        return nativeBoolToBooleanObject(!bytes.Equal(leftVal, rightVal))
     default:
         return newError("unknown operator: %s %s %s",
             left.Type(), operator, right.Type())
@@ -377,14 +483,21 @@ func evalExpressions(exps []ast.Expression,
     return result
 }
 
-func applyFunction(fn object.Object, args []object.Object) object.Object {
+func applyFunction(fn object.Object, args []object.Object,
+    host *object.Host) object.Object {
     switch fn := fn.(type) {
     case *object.Function:
-        extendedEnv := extendFunctionEnv(fn, args)
+        extendedEnv := extendFunctionEnv(fn, args, host)
         evaluated   := Eval(fn.Body, extendedEnv)
         return unwrapReturnValue(evaluated)
     case *object.Builtin:
-        if result := fn.Fn(args...); result != nil {
+        result := fn.Fn(host, args...)
+        if serve, ok := result.(*object.Serve); ok {
+            result = serve.Run(host, func(host *object.Host, request object.Object) object.Object {
+                return applyFunction(serve.Handler, []object.Object{request}, host)
+            })
+        }
+        if result != nil {
             return result
         }
         return NULL
@@ -393,8 +506,34 @@ func applyFunction(fn object.Object, args []object.Object) object.Object {
     }
 }
 
-func extendFunctionEnv(fn *object.Function, args []object.Object) *object.Environment {
-    env := object.NewEnclosedEnvironment(fn.Env)
+// a hash field of that name wins, otherwise receiver.f(args) is f(receiver, args)
+func evalMethodCall(receiver object.Object, name string,
+    args []object.Object, env *object.Environment) object.Object {
+
+    if hash, ok := receiver.(*object.Hash); ok {
+        key := &object.String{Value: name}
+        if pair, ok := hash.Pairs[key.HashKey()]; ok {
+            return applyFunction(pair.Value, args, env.Host())
+        }
+    }
+
+    function, ok := env.Get(name)
+    if !ok {
+        function, ok = Builtins[name]
+    }
+    if !ok {
+        return newError("unknown method: %s", name)
+    }
+
+    args = append([]object.Object{receiver}, args...)
+    return applyFunction(function, args, env.Host())
+}
+
+// the body runs with the host of the caller rather than that of the
+// definition, so that a serve handler hands its request host down
+func extendFunctionEnv(fn *object.Function, args []object.Object,
+    host *object.Host) *object.Environment {
+    env := object.NewEnclosedEnvironmentWithHost(fn.Env, host)
 
     for idx, param := range fn.Parameters {
         env.Set(param.Value, args[idx])
@@ -436,9 +575,10 @@ func isTruthy(o object.Object) bool {
     }
 }
 
+// an exit unwinds the evaluation just like an error does
 func isError(obj object.Object) bool {
     if obj != nil {
-        return obj.Type() == object.ERROR_OBJ
+        return obj.Type() == object.ERROR_OBJ || obj.Type() == object.EXIT_OBJ
     }
     return false
 }
@@ -1,7 +1,16 @@
 package evaluator
 
 import (
+    "bytes"
+    "io"
+    "math/rand"
+    "net/http"
+    "net/http/httptest"
+    "os"
+    "path/filepath"
+    "strings"
     "testing"
+    "time"
 
     "myMonkey/lexer"
     "myMonkey/object"
@@ -345,6 +354,23 @@ func TestStringConcatenation(t *testing.T) {
     }
 }
 
+func TestStringComparison(t *testing.T) {
+    tests := []struct {
+        input    string
+        expected bool
+    }{
+        {`"a" + "b" == "ab"`, true},
+        {`"x" == "y"`, false},
+        {`"x" != "y"`, true},
+        {`bytes("ab") == bytes("a") + bytes("b")`, true},
+        {`bytes("ab") != bytes("ab")`, false},
+    }
+
+    for _, tt := range tests {
+        testBooleanObject(t, testEval(tt.input), tt.expected)
+    }
+}
+
 func TestBuiltinFunctions(t *testing.T) {
     tests := []struct {
         input    string
@@ -409,6 +435,431 @@ func TestBuiltinFunctions(t *testing.T) {
     }
 }
 
+func TestTypeBuiltins(t *testing.T) {
+    tests := []struct {
+        input    string
+        expected interface{}
+    }{
+        {`type(1)`, "INTEGER"},
+        {`type("one")`, "STRING"},
+        {`type(true)`, "BOOLEAN"},
+        {`type([1])`, "ARRAY"},
+        {`type({})`, "HASH"},
+        {`type(fn() {})`, "FUNCTION"},
+        {`type(len)`, "BUILTIN"},
+        {`type(if (false) { 1 })`, "NULL"},
+        {`is_int(1)`, true},
+        {`is_int("1")`, false},
+        {`is_string("1")`, true},
+        {`is_array([])`, true},
+        {`is_array({})`, false},
+        {`is_hash({})`, true},
+        {`is_fn(fn() {})`, true},
+        {`is_fn(len)`, true},
+        {`is_fn(1)`, false},
+        {`int("42")`, 42},
+        {`int("-7")`, -7},
+        {`int(5)`, 5},
+        {`int(true)`, 1},
+        {`int(false)`, 0},
+        {`int("4x2")`, &object.Error{Message: `could not convert "4x2" to INTEGER`}},
+        {`int([])`, &object.Error{Message: "argument to `int` not supported, got ARRAY"}},
+        {`str(42)`, "42"},
+        {`str("a")`, "a"},
+        {`str(true)`, "true"},
+        {`str([1, 2])`, "[1, 2]"},
+        {`bool(0)`, true},
+        {`bool("")`, true},
+        {`bool(false)`, false},
+        {`bool(if (false) { 1 })`, false},
+        {`bool(1) == true`, true},
+        {`if (bool(false)) { 1 } else { 2 }`, 2},
+        {`type(1, 2)`, &object.Error{Message: "wrong number of arguments. got=2, want=1"}},
+    }
+
+    for _, tt := range tests {
+        evaluated := testEval(tt.input)
+
+        switch expected := tt.expected.(type) {
+        case int:
+            testIntegerObject(t, evaluated, int64(expected))
+        case bool:
+            testBooleanObject(t, evaluated, expected)
+        case string:
+            testStringObject(t, evaluated, expected)
+        case *object.Error:
+            testErrorObject(t, evaluated, expected.Message)
+        }
+    }
+}
+
+func TestMathBuiltins(t *testing.T) {
+    tests := []struct {
+        input    string
+        expected interface{}
+    }{
+        {`abs(-5)`, 5},
+        {`abs(5)`, 5},
+        {`min(3, 1, 2)`, 1},
+        {`min([4, -2, 9])`, -2},
+        {`max(3, 1, 2)`, 3},
+        {`max([4, -2, 9])`, 9},
+        {`min([])`, "`min` of no values"},
+        {`max(1, "2")`, "arguments to `max` must be INTEGER, got STRING"},
+        {`pow(2, 10)`, 1024},
+        {`pow(-3, 3)`, -27},
+        {`pow(7, 0)`, 1},
+        {`pow(2, -1)`, "negative exponent for `pow`: -1"},
+        {`sqrt(16)`, 4},
+        {`sqrt(17)`, 4},
+        {`sqrt(0)`, 0},
+        {`sqrt(9223372036854775807)`, 3037000499},
+        {`sqrt(3037000499 * 3037000499)`, 3037000499},
+        {`sqrt(-4)`, "square root of negative number: -4"},
+        {`abs("1")`, "argument to `abs` must be INTEGER, got STRING"},
+        {`abs(-9223372036854775807 - 1)`, "integer overflow: abs(-9223372036854775808)"},
+        {`random(5, 5)`, "empty range for `random`: [5, 5)"},
+    }
+
+    for _, tt := range tests {
+        evaluated := testEval(tt.input)
+
+        switch expected := tt.expected.(type) {
+        case int:
+            testIntegerObject(t, evaluated, int64(expected))
+        case string:
+            testErrorObject(t, evaluated, expected)
+        }
+    }
+}
+
+func TestSeededRandom(t *testing.T) {
+    input := `[random(), random(10), random(-5, 5)]`
+
+    want := rand.New(rand.NewSource(42))
+    expected := []int64{want.Int63(), want.Int63n(10), -5 + want.Int63n(10)}
+
+    for run := 0; run < 2; run++ {
+        host := object.NewHost()
+        host.Seed(42)
+
+        l := lexer.New(input)
+        p := parser.New(l)
+        env := object.NewEnvironmentWithHost(host)
+
+        result, ok := Eval(p.ParseProgram(), env).(*object.Array)
+        if !ok {
+            t.Fatalf("object is not Array. got=%T", result)
+        }
+
+        for i, e := range expected {
+            testIntegerObject(t, result.Elements[i], e)
+        }
+    }
+}
+
+func TestFileBuiltins(t *testing.T) {
+    fs, err := object.NewDirFileSystem(t.TempDir(), false)
+    if err != nil {
+        t.Fatalf("NewDirFileSystem failed: %s", err)
+    }
+    defer fs.Close()
+
+    host := object.NewHost()
+    host.FS = fs
+
+    input := `
+    write_file("config.txt", "debug");
+    [read_file("config.txt"), exists("config.txt"), exists("nope"), list_dir(".")]
+    `
+
+    l := lexer.New(input)
+    p := parser.New(l)
+    evaluated := Eval(p.ParseProgram(), object.NewEnvironmentWithHost(host))
+
+    result, ok := evaluated.(*object.Array)
+    if !ok {
+        t.Fatalf("object is not Array. got=%T (%+v)", evaluated, evaluated)
+    }
+
+    testStringObject(t, result.Elements[0], "debug")
+    testBooleanObject(t, result.Elements[1], true)
+    testBooleanObject(t, result.Elements[2], false)
+
+    names, ok := result.Elements[3].(*object.Array)
+    if !ok || len(names.Elements) != 1 {
+        t.Fatalf("list_dir returned %+v", result.Elements[3])
+    }
+    testStringObject(t, names.Elements[0], "config.txt")
+}
+
+func TestFileBuiltinsDeniedByDefault(t *testing.T) {
+    tests := []struct {
+        input    string
+        expected string
+    }{
+        {`read_file("a")`, "read_file: file access denied by host"},
+        {`write_file("a", "b")`, "write_file: file access denied by host"},
+        {`list_dir(".")`, "list_dir: file access denied by host"},
+        {`exists("a")`, "exists: file access denied by host"},
+    }
+
+    for _, tt := range tests {
+        evaluated := testEval(tt.input)
+
+        testErrorObject(t, evaluated, tt.expected)
+    }
+}
+
+func TestJsonBuiltins(t *testing.T) {
+    tests := []struct {
+        input    string
+        expected interface{}
+    }{
+        {`json_encode(1)`, "1"},
+        {`json_encode("a<b")`, `"a<b"`},
+        {`json_encode([1, true, if (false) { 1 }])`, `[1,true,null]`},
+        {`json_encode({"b": 1, "a": [], "c": {"d": "e"}})`, `{"a":[],"b":1,"c":{"d":"e"}}`},
+        {`json_encode({"b": 1, "a": [2]}, true)`, "{\n  \"a\": [\n    2\n  ],\n  \"b\": 1\n}"},
+        {`json_encode([1], "    ")`, "[\n    1\n]"},
+        {`json_encode(fn(x) { x })`, &object.Error{Message: "json_encode: unsupported value FUNCTION"}},
+        {`json_encode([len])`, &object.Error{Message: "json_encode: unsupported value BUILTIN"}},
+        {`json_encode({1: 2})`, &object.Error{Message: "json_encode: unsupported hash key INTEGER, want STRING"}},
+        {`json_decode(json_encode({"a": [1, 2]}))["a"][1]`, 2},
+        {`json_decode("[1, 2")`, &object.Error{Message: "json_decode: unexpected EOF"}},
+        {`json_decode("1.5")`, &object.Error{Message: "json_decode: number 1.5 is not an integer"}},
+        {`json_decode("1 2")`, &object.Error{Message: "json_decode: unexpected data after top-level value"}},
+    }
+
+    for _, tt := range tests {
+        evaluated := testEval(tt.input)
+
+        switch expected := tt.expected.(type) {
+        case int:
+            testIntegerObject(t, evaluated, int64(expected))
+        case string:
+            testStringObject(t, evaluated, expected)
+        case *object.Error:
+            testErrorObject(t, evaluated, expected.Message)
+        }
+    }
+}
+
+func TestJsonDecode(t *testing.T) {
+    env := object.NewEnvironment()
+    env.Set("doc", &object.String{Value: `{"name": "monkey", "tags": ["a", "b"], "age": 3, "ok": true, "none": null}`})
+
+    input := `let v = json_decode(doc);
+    [v["name"], v["tags"][1], v["age"], v["ok"], v["none"]]`
+
+    l := lexer.New(input)
+    p := parser.New(l)
+    evaluated := Eval(p.ParseProgram(), env)
+
+    result, ok := evaluated.(*object.Array)
+    if !ok {
+        t.Fatalf("object is not Array. got=%T (%+v)", evaluated, evaluated)
+    }
+
+    testStringObject(t, result.Elements[0], "monkey")
+    testStringObject(t, result.Elements[1], "b")
+    testIntegerObject(t, result.Elements[2], 3)
+    testBooleanObject(t, result.Elements[3], true)
+    testNullObject(t, result.Elements[4])
+}
+
+func TestRegexBuiltins(t *testing.T) {
+    tests := []struct {
+        input    string
+        expected interface{}
+    }{
+        {`match("(\w+)@(\w+)", "mail bob@example now")`, []string{"bob@example", "bob", "example"}},
+        {`match(regex("\d+"), "abc 42")`, []string{"42"}},
+        {`match("\d+", "abc")`, nil},
+        {`match("a(x)?b", "ab")[1]`, nil},
+        {`len(find_all("\d", "a1b2c3"))`, 3},
+        {`find_all("(\d)(\w)", "1a 2b")[1]`, []string{"2b", "2", "b"}},
+        {`captures("(?P<user>\w+)@(?P<host>\w+)", "bob@example")["host"]`, "example"},
+        {`captures("(?P<user>\w+)@", "nobody")`, nil},
+        {`replace("(\w+)@(\w+)", "bob@example", "$2 at ${1}")`, "example at bob"},
+        {`split(",\s*", "a, b,c")`, []string{"a", "b", "c"}},
+        {`type(regex("a+"))`, "REGEX"},
+        {`match("(", "x")`, &object.Error{Message: "match: error parsing regexp: missing closing ): `(`"}},
+        {`match(1, "x")`, &object.Error{Message: "pattern to `match` must be REGEX or STRING, got INTEGER"}},
+    }
+
+    for _, tt := range tests {
+        evaluated := testEval(tt.input)
+
+        switch expected := tt.expected.(type) {
+        case int:
+            testIntegerObject(t, evaluated, int64(expected))
+        case string:
+            testStringObject(t, evaluated, expected)
+        case nil:
+            testNullObject(t, evaluated)
+        case []string:
+            array, ok := evaluated.(*object.Array)
+            if !ok {
+                t.Errorf("obj not Array. got=%T (%+v)", evaluated, evaluated)
+                continue
+            }
+            if len(array.Elements) != len(expected) {
+                t.Errorf("wrong num of elements. want=%d, got=%d",
+                    len(expected), len(array.Elements))
+                continue
+            }
+            for i, expectedElem := range expected {
+                testStringObject(t, array.Elements[i], expectedElem)
+            }
+        case *object.Error:
+            testErrorObject(t, evaluated, expected.Message)
+        }
+    }
+}
+
+func TestTimeBuiltins(t *testing.T) {
+    start := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+    tests := []struct {
+        input    string
+        expected interface{}
+    }{
+        {`now()`, start.UnixMilli()},
+        {`let t = now(); sleep(1500); now() - t`, 1500},
+        {`format_time(now())`, "2024-03-01T12:00:00Z"},
+        {`format_time(now() + duration("36h"), "DateOnly")`, "2024-03-03"},
+        {`format_time(0, "2006/01/02 15:04")`, "1970/01/01 00:00"},
+        {`parse_time("2024-03-01T12:00:00Z") == now()`, true},
+        {`parse_time("01.02.2023", "02.01.2006")`, time.Date(2023, 2, 1, 0, 0, 0, 0, time.UTC).UnixMilli()},
+        {`duration("1h30m")`, 5400000},
+        {`format_duration(duration("90m") - duration("15s"))`, "1h29m45s"},
+        {`parse_time("yesterday")`,
+            &object.Error{Message: `parse_time: parsing time "yesterday" as "2006-01-02T15:04:05Z07:00": cannot parse "yesterday" as "2006"`}},
+        {`duration("soon")`, &object.Error{Message: `duration: time: invalid duration "soon"`}},
+        {`sleep("1s")`, &object.Error{Message: "argument to `sleep` must be INTEGER, got STRING"}},
+    }
+
+    for _, tt := range tests {
+        host := object.NewHost()
+        host.Clock = object.NewManualClock(start)
+
+        l := lexer.New(tt.input)
+        p := parser.New(l)
+        evaluated := Eval(p.ParseProgram(), object.NewEnvironmentWithHost(host))
+
+        switch expected := tt.expected.(type) {
+        case int:
+            testIntegerObject(t, evaluated, int64(expected))
+        case int64:
+            testIntegerObject(t, evaluated, expected)
+        case bool:
+            testBooleanObject(t, evaluated, expected)
+        case string:
+            testStringObject(t, evaluated, expected)
+        case *object.Error:
+            testErrorObject(t, evaluated, expected.Message)
+        }
+    }
+}
+
+func TestOutputBuiltins(t *testing.T) {
+    var stdout, stderr bytes.Buffer
+
+    host := object.NewHost()
+    host.Stdout = &stdout
+    host.Stderr = &stderr
+    host.Stdin  = strings.NewReader("first\r\nsecond")
+
+    input := `
+    puts("hello", 1);
+    print("a", [1, 2]);
+    eputs("oops");
+    [read_line(), read_line(), read_line()]
+    `
+
+    l := lexer.New(input)
+    p := parser.New(l)
+    evaluated := Eval(p.ParseProgram(), object.NewEnvironmentWithHost(host))
+
+    if stdout.String() != "hello\n1\na[1, 2]" {
+        t.Errorf("wrong stdout. got=%q", stdout.String())
+    }
+    if stderr.String() != "oops\n" {
+        t.Errorf("wrong stderr. got=%q", stderr.String())
+    }
+
+    result, ok := evaluated.(*object.Array)
+    if !ok {
+        t.Fatalf("object is not Array. got=%T (%+v)", evaluated, evaluated)
+    }
+    testStringObject(t, result.Elements[0], "first")
+    testStringObject(t, result.Elements[1], "second")
+    testNullObject(t, result.Elements[2])
+}
+
+func TestSetBuiltins(t *testing.T) {
+    tests := []struct {
+        input    string
+        expected interface{}
+    }{
+        {`len(set())`, 0},
+        {`len(set([1, 2, 2, 3, 1]))`, 3},
+        {`len(set(1, "1", true, 1))`, 3},
+        {`to_array(set([3, 1, 3, 2]))`, []int{3, 1, 2}},
+        {`to_array(add(set(1), 2))`, []int{1, 2}},
+        {`let s = set(1); add(s, 2); len(s)`, 1},
+        {`to_array(remove(set(1, 2, 3), 2))`, []int{1, 3}},
+        {`contains(set("a", "b"), "b")`, true},
+        {`contains(set("a", "b"), "c")`, false},
+        {`to_array(union(set(1, 2), set(2, 3)))`, []int{1, 2, 3}},
+        {`to_array(intersection(set(1, 2, 3), set(3, 2, 5)))`, []int{2, 3}},
+        {`to_array(difference(set(1, 2, 3), set(2)))`, []int{1, 3}},
+        {`first(to_array(set(7, 8)))`, 7},
+        {`type(set())`, "SET"},
+        {`is_set(set())`, true},
+        {`is_set([])`, false},
+        {`let s = set(1); s == s`, true},
+        {`set(1, 2) == set(2, 1)`, true},
+        {`set(1, 2) == set(1)`, false},
+        {`set(1) != set(2)`, true},
+        {`set(1) < set(2)`, &object.Error{Message: "unknown operator: SET < SET"}},
+        {`set([1], [2])`, &object.Error{Message: "unusable as set element: ARRAY"}},
+        {`add(set(), {})`, &object.Error{Message: "unusable as set element: HASH"}},
+        {`union(set(), [])`, &object.Error{Message: "arguments to `union` must be SET, got SET and ARRAY"}},
+        {`contains([1], 1)`, &object.Error{Message: "argument to `contains` must be SET, got ARRAY"}},
+    }
+
+    for _, tt := range tests {
+        evaluated := testEval(tt.input)
+
+        switch expected := tt.expected.(type) {
+        case int:
+            testIntegerObject(t, evaluated, int64(expected))
+        case bool:
+            testBooleanObject(t, evaluated, expected)
+        case string:
+            testStringObject(t, evaluated, expected)
+        case []int:
+            array, ok := evaluated.(*object.Array)
+            if !ok {
+                t.Errorf("obj not Array. got=%T (%+v)", evaluated, evaluated)
+                continue
+            }
+            if len(array.Elements) != len(expected) {
+                t.Errorf("wrong num of elements. want=%d, got=%d",
+                    len(expected), len(array.Elements))
+                continue
+            }
+            for i, expectedElem := range expected {
+                testIntegerObject(t, array.Elements[i], int64(expectedElem))
+            }
+        case *object.Error:
+            testErrorObject(t, evaluated, expected.Message)
+        }
+    }
+}
+
 func TestArrayLiterals(t *testing.T) {
     input := "[1, 2 * 2, 3 + 3]"
 
@@ -471,6 +922,14 @@ func TestArrayIndexExpressions(t *testing.T) {
         },
         {
             "[1, 2, 3][-1]",
+            3,
+        },
+        {
+            "[1, 2, 3][-3]",
+            1,
+        },
+        {
+            "[1, 2, 3][-4]",
             nil,
         },
     }
@@ -486,6 +945,46 @@ func TestArrayIndexExpressions(t *testing.T) {
     }
 }
 
+func TestStringIndexAndSliceExpressions(t *testing.T) {
+    tests := []struct {
+        input    string
+        expected interface{}
+    }{
+        {`"monkey"[0]`, "m"},
+        {`"monkey"[-1]`, "y"},
+        {`"monkey"[6]`, nil},
+        {`"monkey"[1:3]`, "on"},
+        {`"monkey"[:-3]`, "mon"},
+        {`"monkey"[3:]`, "key"},
+        {`"monkey"[:]`, "monkey"},
+        {`"monkey"[4:2]`, ""},
+        {`"monkey"[-100:100]`, "monkey"},
+        {`len([1, 2, 3, 4][1:3])`, 2},
+        {`[1, 2, 3, 4][1:3][0]`, 2},
+        {`[1, 2, 3, 4][:-1][-1]`, 3},
+        {`[1, 2, 3, 4][-2:][0]`, 3},
+        {`len([1, 2, 3][5:])`, 0},
+        {`let a = [1, 2, 3]; let b = a[:]; len(push(b, 4)) + len(a)`, 7},
+        {`[1, 2]["a":]`, &object.Error{Message: "slice bounds must be INTEGER, got STRING"}},
+        {`{}[1:2]`, &object.Error{Message: "slice operator not supported: HASH"}},
+    }
+
+    for _, tt := range tests {
+        evaluated := testEval(tt.input)
+
+        switch expected := tt.expected.(type) {
+        case int:
+            testIntegerObject(t, evaluated, int64(expected))
+        case string:
+            testStringObject(t, evaluated, expected)
+        case nil:
+            testNullObject(t, evaluated)
+        case *object.Error:
+            testErrorObject(t, evaluated, expected.Message)
+        }
+    }
+}
+
 func TestHashLiterals(t *testing.T) {
     input := `let two = "two";
     {
@@ -572,6 +1071,370 @@ func TestHashIndexExpressions(t *testing.T) {
     }
 }
 
+func TestFieldExpressions(t *testing.T) {
+    tests := []struct {
+        input    string
+        expected interface{}
+    }{
+        {`let person = {"name": "Anna", "age": 30}; person.name`, "Anna"},
+        {`let person = {"name": "Anna", "age": 30}; person.age + 1`, 31},
+        {`{"a": {"b": [1, 2]}}.a.b[1]`, 2},
+        {`{"a": 1}.b`, &object.Error{Message: "unknown field: b"}},
+        {`{"a": 1}["b"]`, nil},
+        {`[1].length`, &object.Error{Message: "field access not supported: ARRAY"}},
+    }
+
+    for _, tt := range tests {
+        evaluated := testEval(tt.input)
+
+        switch expected := tt.expected.(type) {
+        case int:
+            testIntegerObject(t, evaluated, int64(expected))
+        case string:
+            testStringObject(t, evaluated, expected)
+        case nil:
+            testNullObject(t, evaluated)
+        case *object.Error:
+            testErrorObject(t, evaluated, expected.Message)
+        }
+    }
+}
+
+func TestBytes(t *testing.T) {
+    tests := []struct {
+        input    string
+        expected interface{}
+    }{
+        {`len(bytes("hello"))`, 5},
+        {`bytes("hi")[0]`, 104},
+        {`bytes("hi")[-1]`, 105},
+        {`bytes("hi")[2]`, nil},
+        {`str(bytes([104, 105]))`, "hi"},
+        {`str(bytes("monkey")[1:3])`, "on"},
+        {`str(bytes("mon") + bytes("key"))`, "monkey"},
+        {`type(bytes(""))`, "BYTES"},
+        {`is_bytes(bytes("")) == !is_bytes("")`, true},
+        {`hex_encode(bytes([0, 15, 255]))`, "000fff"},
+        {`hex_encode("hi")`, "6869"},
+        {`str(hex_decode("6869"))`, "hi"},
+        {`base64_encode("hello")`, "aGVsbG8="},
+        {`str(base64_decode("aGVsbG8="))`, "hello"},
+        {`{bytes("k"): 1}[bytes("k")]`, 1},
+        {`bytes([256])`, &object.Error{Message: "byte out of range: 256"}},
+        {`bytes(1)`, &object.Error{Message: "argument to `bytes` not supported, got INTEGER"}},
+        {`hex_decode("zz")`, &object.Error{Message: "hex_decode: encoding/hex: invalid byte: U+007A 'z'"}},
+        {`base64_encode(1)`, &object.Error{Message: "argument to `base64_encode` must be BYTES or STRING, got INTEGER"}},
+        {`bytes("a") - bytes("b")`, &object.Error{Message: "unknown operator: BYTES - BYTES"}},
+    }
+
+    for _, tt := range tests {
+        evaluated := testEval(tt.input)
+
+        switch expected := tt.expected.(type) {
+        case int:
+            testIntegerObject(t, evaluated, int64(expected))
+        case bool:
+            testBooleanObject(t, evaluated, expected)
+        case string:
+            testStringObject(t, evaluated, expected)
+        case nil:
+            testNullObject(t, evaluated)
+        case *object.Error:
+            testErrorObject(t, evaluated, expected.Message)
+        }
+    }
+}
+
+func TestHashBuiltins(t *testing.T) {
+    tests := []struct {
+        input    string
+        expected interface{}
+    }{
+        {`sha256("abc")`, "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad"},
+        {`sha256(bytes([0, 1]))`, "b413f47d13ee2fe6c845b2ee141af81de858df4ec549a58b7970bb96645bc8d2"},
+        {`sha1("abc")`, "a9993e364706816aba3e25717850c26c9cd0d89d"},
+        {`md5("abc")`, "900150983cd24fb0d6963f7d28e17f72"},
+        {`crc32("abc")`, "352441c2"},
+        {`hmac("sha256", "key", "The quick brown fox jumps over the lazy dog")`,
+            "f7bc83f430538424b13298e6aa6fb143ef4d59a14946175997479dbc2d1a3cd8"},
+        {`hmac("md5", bytes("key"), "msg")`, "18e3548c59ad40dd03907b7aeee71d67"},
+        {`sha256(1)`, &object.Error{Message: "argument to `sha256` must be BYTES or STRING, got INTEGER"}},
+        {`hmac("sha512", "k", "m")`, &object.Error{Message: `hmac: unknown algorithm "sha512"`}},
+        {`hmac("md5", "k")`, &object.Error{Message: "wrong number of arguments. got=2, want=3"}},
+    }
+
+    for _, tt := range tests {
+        evaluated := testEval(tt.input)
+
+        switch expected := tt.expected.(type) {
+        case string:
+            testStringObject(t, evaluated, expected)
+        case *object.Error:
+            testErrorObject(t, evaluated, expected.Message)
+        }
+    }
+}
+
+func TestProcessBuiltins(t *testing.T) {
+    host := object.NewHost()
+    host.Args = []string{"-v", "input.txt"}
+    host.Env  = map[string]string{"HOME": "/home/monkey"}
+
+    tests := []struct {
+        input    string
+        expected interface{}
+    }{
+        {`len(args())`, 2},
+        {`args()[1]`, "input.txt"},
+        {`env("HOME")`, "/home/monkey"},
+        {`env("NOPE")`, nil},
+        {`env()["HOME"]`, "/home/monkey"},
+        {`exit(3)`, &object.Exit{Code: 3}},
+        {`exit()`, &object.Exit{Code: 0}},
+        {`let f = fn() { exit(2); 1 }; [f(), 5]`, &object.Exit{Code: 2}},
+        {`if (true) { exit(4) }; puts("unreachable")`, &object.Exit{Code: 4}},
+        {`exit("1")`, &object.Error{Message: "argument to `exit` must be INTEGER, got STRING"}},
+    }
+
+    for _, tt := range tests {
+        l := lexer.New(tt.input)
+        p := parser.New(l)
+        evaluated := Eval(p.ParseProgram(), object.NewEnvironmentWithHost(host))
+
+        switch expected := tt.expected.(type) {
+        case int:
+            testIntegerObject(t, evaluated, int64(expected))
+        case string:
+            testStringObject(t, evaluated, expected)
+        case nil:
+            testNullObject(t, evaluated)
+        case *object.Exit:
+            exit, ok := evaluated.(*object.Exit)
+            if !ok {
+                t.Errorf("object is not Exit. got=%T (%+v)", evaluated, evaluated)
+                continue
+            }
+            if exit.Code != expected.Code {
+                t.Errorf("wrong exit code. want=%d, got=%d", expected.Code, exit.Code)
+            }
+        case *object.Error:
+            testErrorObject(t, evaluated, expected.Message)
+        }
+    }
+}
+
+func TestExecBuiltin(t *testing.T) {
+    dir := t.TempDir()
+    err := os.Mkdir(filepath.Join(dir, "sub"), 0755)
+    if err != nil {
+        t.Fatal(err)
+    }
+
+    fs, err := object.NewDirFileSystem(dir, false)
+    if err != nil {
+        t.Fatal(err)
+    }
+    defer fs.Close()
+
+    host := object.NewHost()
+    host.AllowedCommands = []string{"sh"}
+    host.FS = fs
+
+    tests := []struct {
+        input    string
+        expected interface{}
+    }{
+        {`exec("sh", ["-c", "echo out; echo err >&2; exit 3"])["stdout"]`, "out\n"},
+        {`exec("sh", ["-c", "echo out; echo err >&2; exit 3"])["stderr"]`, "err\n"},
+        {`exec("sh", ["-c", "exit 3"])["status"]`, 3},
+        {`exec("sh", ["-c", "exit 3"])["timed_out"]`, false},
+        {`exec("sh", ["-c", "cat"], {"stdin": "piped"})["stdout"]`, "piped"},
+        {`exec("sh", ["-c", "pwd"])["stdout"]`, dir + "\n"},
+        {`exec("sh", ["-c", "pwd"], {"dir": "sub"})["stdout"]`, filepath.Join(dir, "sub") + "\n"},
+        {`exec("sh", [], {"dir": ".."})`, &object.Error{Message: "exec: statat ..: path escapes from parent"}},
+        {`exec("sh", ["-c", "sleep 5"], {"timeout": 50})["timed_out"]`, true},
+        {`exec("ls")`, &object.Error{Message: `exec: command "ls" not allowed by host`}},
+        {`exec("sh", "-c")`, &object.Error{Message: "second argument to `exec` must be ARRAY, got STRING"}},
+        {`exec("sh", [], {"shell": true})`, &object.Error{Message: `exec: unknown option "shell"`}},
+        {`exec("sh", [], {"timeout": "1s"})`, &object.Error{Message: "exec: timeout must be INTEGER, got STRING"}},
+    }
+
+    for _, tt := range tests {
+        l := lexer.New(tt.input)
+        p := parser.New(l)
+        evaluated := Eval(p.ParseProgram(), object.NewEnvironmentWithHost(host))
+
+        switch expected := tt.expected.(type) {
+        case int:
+            testIntegerObject(t, evaluated, int64(expected))
+        case bool:
+            testBooleanObject(t, evaluated, expected)
+        case string:
+            testStringObject(t, evaluated, expected)
+        case *object.Error:
+            testErrorObject(t, evaluated, expected.Message)
+        }
+    }
+
+    evaluated := testEval(`exec("sh", ["-c", "true"])`)
+    testErrorObject(t, evaluated, `exec: command "sh" not allowed by host`)
+
+    host.FS = nil
+    evaluated = Eval(parser.New(lexer.New(`exec("sh", [], {"dir": "sub"})`)).ParseProgram(),
+        object.NewEnvironmentWithHost(host))
+    testErrorObject(t, evaluated, "exec: dir needs a host file system")
+}
+
+func TestHTTPBuiltins(t *testing.T) {
+    server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        switch r.URL.Path {
+        case "/slow":
+            time.Sleep(200 * time.Millisecond)
+        case "/missing":
+            http.NotFound(w, r)
+            return
+        }
+
+        body, _ := io.ReadAll(r.Body)
+        w.Header().Set("X-Method", r.Method)
+        io.WriteString(w, r.Header.Get("X-Token") + ":" + string(body))
+    }))
+    defer server.Close()
+
+    host := object.NewHost()
+    url  := `"` + server.URL
+
+    tests := []struct {
+        input    string
+        expected interface{}
+    }{
+        {`http_get(` + url + `/")["status"]`, 200},
+        {`http_get(` + url + `/")["body"]`, ":"},
+        {`http_get(` + url + `/", {"headers": {"X-Token": "t"}})["body"]`, "t:"},
+        {`http_get(` + url + `/")["headers"]["X-Method"]`, "GET"},
+        {`http_get(` + url + `/missing")["status"]`, 404},
+        {`http_post(` + url + `/", "payload")["body"]`, ":payload"},
+        {`http_post(` + url + `/", "p")["headers"]["X-Method"]`, "POST"},
+        {`http_request("PUT", ` + url + `/", {"body": "b"})["headers"]["X-Method"]`, "PUT"},
+        {`http_get(` + url + `/slow", {"timeout": 20})`,
+            &object.Error{Message: `http_get: Get "` + server.URL + `/slow": context deadline exceeded`}},
+        {`http_get(` + url + `/", {"retries": 1})`, &object.Error{Message: `http_get: unknown option "retries"`}},
+        {`http_get(` + url + `/", {"headers": {"X": 1}})`,
+            &object.Error{Message: "http_get: headers must map STRING to STRING, got STRING to INTEGER"}},
+        {`http_post(` + url + `/", 1)`, &object.Error{Message: "second argument to `http_post` must be STRING, got INTEGER"}},
+    }
+
+    for _, tt := range tests {
+        l := lexer.New(tt.input)
+        p := parser.New(l)
+        evaluated := Eval(p.ParseProgram(), object.NewEnvironmentWithHost(host))
+
+        switch expected := tt.expected.(type) {
+        case int:
+            testIntegerObject(t, evaluated, int64(expected))
+        case string:
+            testStringObject(t, evaluated, expected)
+        case *object.Error:
+            testErrorObject(t, evaluated, expected.Message)
+        }
+    }
+
+    host.NetworkDisabled = true
+    l := lexer.New(`http_get(` + url + `/")`)
+    evaluated := Eval(parser.New(l).ParseProgram(), object.NewEnvironmentWithHost(host))
+    testErrorObject(t, evaluated, "http_get: network access disabled by host")
+}
+
+func TestServe(t *testing.T) {
+    evaluated := testEval(`serve(":0", 1)`)
+    testErrorObject(t, evaluated, "second argument to `serve` must be a function, got INTEGER")
+}
+
+func TestCSVBuiltins(t *testing.T) {
+    tests := []struct {
+        input    string
+        expected interface{}
+    }{
+        {"len(csv_parse(\"a,b\n1,2\n\"))", 2},
+        {"csv_parse(\"a,b\n1,2\n\")[1][0]", "1"},
+        {`csv_parse("a;b", {"delimiter": ";"})[0][1]`, "b"},
+        {"let rows = csv_parse(\"name,age\nanna,30\nbob,4\", {\"header\": true}); rows[1][\"name\"]", "bob"},
+        {"len(csv_parse(\"name\n\", {\"header\": true}))", 0},
+        {`csv_format([["a", "b"], [1, true], ["x,y", if (false) { 1 }]])`, "a,b\n1,true\n\"x,y\",\n"},
+        {`csv_format([["a", "b"]], {"delimiter": "|"})`, "a|b\n"},
+        {`csv_format([{"b": 2, "a": 1}, {"a": 3}])`, "a,b\n1,2\n3,\n"},
+        {`csv_format([{"b": 2, "a": 1}], {"header": ["b", "a"]})`, "b,a\n2,1\n"},
+        {"csv_parse(csv_format([[\"x, y\", \"multi\nline\"]]))[0][1]", "multi\nline"},
+        {"csv_parse(\"a,b\n1\n\")", &object.Error{Message: "csv_parse: record on line 2: wrong number of fields"}},
+        {`csv_parse("a", {"delimiter": ";;"})`, &object.Error{Message: "csv_parse: delimiter must be a single character, got ;;"}},
+        {`csv_format([[[1]]])`, &object.Error{Message: "csv_format: unsupported cell ARRAY"}},
+        {`csv_format([1])`, &object.Error{Message: "csv_format: rows must be ARRAY or HASH, got INTEGER"}},
+        {"csv_format([[\"a\", \"b\"]], {\"delimiter\": \"\n\"})",
+            &object.Error{Message: `csv_format: invalid delimiter "\n"`}},
+        {"csv_parse(\"a\", {\"delimiter\": \"\uFFFD\"})",
+            &object.Error{Message: "csv_parse: invalid delimiter \"\uFFFD\""}},
+        {`csv_format([{1: "a"}])`, &object.Error{Message: "csv_format: hash keys must be STRING, got INTEGER"}},
+        {`csv_format([{"a": 1}, {"a": 2, 3: 4}])`,
+            &object.Error{Message: "csv_format: hash keys must be STRING, got INTEGER"}},
+    }
+
+    for _, tt := range tests {
+        evaluated := testEval(tt.input)
+
+        switch expected := tt.expected.(type) {
+        case int:
+            testIntegerObject(t, evaluated, int64(expected))
+        case string:
+            testStringObject(t, evaluated, expected)
+        case *object.Error:
+            testErrorObject(t, evaluated, expected.Message)
+        }
+    }
+}
+
+// the results stay within the range of preallocated integers, see
+// object.NewInteger
+func BenchmarkSmallIntegers(b *testing.B) {
+    l := lexer.New(`
+    let a = 10;
+    let b = a * 3 - 7;
+    [a + b, a - b, b / 2, -a, a * b - 100, len([a, b]), a < b, a == b]
+    `)
+    program := parser.New(l).ParseProgram()
+
+    b.ReportAllocs()
+    b.ResetTimer()
+    for i := 0; i < b.N; i++ {
+        Eval(program, object.NewEnvironment())
+    }
+}
+
+func TestMethodCalls(t *testing.T) {
+    tests := []struct {
+        input    string
+        expected interface{}
+    }{
+        {`[1, 2].push(3).len()`, 3},
+        {`"monkey".len()`, 6},
+        {`let double = fn(x) { x * 2 }; 21.double()`, 42},
+        {`let add = fn(a, b) { a + b }; 1.add(2).add(3)`, 6},
+        {`let counter = {"next": fn(x) { x + 1 }}; counter.next(1)`, 2},
+        {`let len = fn(x) { 0 }; {"len": fn() { 1 }}.len()`, 1},
+        {`1.nope()`, &object.Error{Message: "unknown method: nope"}},
+    }
+
+    for _, tt := range tests {
+        evaluated := testEval(tt.input)
+
+        switch expected := tt.expected.(type) {
+        case int:
+            testIntegerObject(t, evaluated, int64(expected))
+        case *object.Error:
+            testErrorObject(t, evaluated, expected.Message)
+        }
+    }
+}
+
 func testEval(input string) object.Object {
     l := lexer.New(input)
     p := parser.New(l)
@@ -596,6 +1459,21 @@ func testIntegerObject(t *testing.T, obj object.Object, expected int64) bool {
     return true
 }
 
+func testErrorObject(t *testing.T, obj object.Object, expected string) bool {
+    errObj, ok := obj.(*object.Error)
+    if !ok {
+        t.Errorf("object is not Error. got=%T (%+v)", obj, obj)
+        return false
+    }
+    if errObj.Message != expected {
+        t.Errorf("wrong error message. expected=%q, got=%q",
+            expected, errObj.Message)
+        return false
+    }
+
+    return true
+}
+
 func testBooleanObject(t *testing.T, obj object.Object, expected bool) bool {
     result, ok := obj.(*object.Boolean)
     if !ok {
@@ -610,6 +1488,20 @@ func testBooleanObject(t *testing.T, obj object.Object, expected bool) bool {
     return true
 }
 
+func testStringObject(t *testing.T, obj object.Object, expected string) bool {
+    result, ok := obj.(*object.String)
+    if !ok {
+        t.Errorf("object is not String. got=%T (%+v)", obj, obj)
+        return false
+    }
+    if result.Value != expected {
+        t.Errorf("object has wrong value. got=%q, want=%q",
+            result.Value, expected)
+        return false
+    }
+    return true
+}
+
 func testNullObject(t *testing.T, obj object.Object) bool {
     if obj != NULL {
         t.Errorf("object is not NULL. got=%T (%+v)", obj, obj)
@@ -91,6 +91,8 @@ func (l *Lexer) NextToken() token.Token {
         tok = newToken(token.SEMICOLON, l.ch)
     case ':':
         tok = newToken(token.COLON, l.ch)
+    case '.':
+        tok = newToken(token.DOT, l.ch)
     case 0:
         tok.Type    = token.EOF
         tok.Literal = ""
@@ -112,9 +114,10 @@ func (l *Lexer) NextToken() token.Token {
     return tok
 }
 
+// digits are allowed after the first letter, as in base64_encode
 func (l *Lexer) readIdentifier() string {
     position := l.position
-    for isLetter(l.ch) {
+    for isLetter(l.ch) || isDigit(l.ch) {
         l.readChar()
     }
     return l.input[position : l.position]
@@ -30,6 +30,8 @@ if (5 < 10) {
 "foo bar"
 [1, 2];
 {"foo": "bar"}
+person.name
+md5 x1y
 `
 
     tests := []struct {
@@ -122,6 +124,11 @@ if (5 < 10) {
         {token.COLON, ":"},
         {token.STRING, "bar"},
         {token.RBRACE, "}"},
+        {token.IDENT, "person"},
+        {token.DOT, "."},
+        {token.IDENT, "name"},
+        {token.IDENT, "md5"},
+        {token.IDENT, "x1y"},
         {token.EOF, ""},
     }
     
@@ -3,10 +3,21 @@ import (
     "fmt"
     "os"
     "os/user"
+    "myMonkey/object"
     "myMonkey/repl"
 )
 
+// monkey             => the REPL
+// monkey file args.. => runs file, passing it args
 func main() {
+    if len(os.Args) > 1 {
+        host := object.NewHost()
+        host.Args = os.Args[2:]
+        host.Env  = object.Environ()
+
+        os.Exit(repl.RunFile(os.Args[1], host))
+    }
+
     user, err := user.Current()
     if err != nil {
         panic(err)
@@ -1,17 +1,86 @@
 package object
 
-import "fmt"
+import (
+    "fmt"
+    "io"
+)
 
 var Builtins = []struct {
     Name    string
     Builtin *Builtin
 } {
-    {"len",    &Builtin{Fn: BuiltinFuncLen},},
-    {"first",  &Builtin{Fn: BuiltinFuncFirst},},
-    {"last",   &Builtin{Fn: BuiltinFuncLast},},
-    {"rest",   &Builtin{Fn: BuiltinFuncRest},},
-    {"push",   &Builtin{Fn: BuiltinFuncPush},},
-    {"puts",   &Builtin{Fn: BuiltinFuncPuts},},
+    {"len",             &Builtin{Fn: BuiltinFuncLen},},
+    {"first",           &Builtin{Fn: BuiltinFuncFirst},},
+    {"last",            &Builtin{Fn: BuiltinFuncLast},},
+    {"rest",            &Builtin{Fn: BuiltinFuncRest},},
+    {"push",            &Builtin{Fn: BuiltinFuncPush},},
+    {"puts",            &Builtin{Fn: BuiltinFuncPuts},},
+    {"type",            &Builtin{Fn: BuiltinFuncType},},
+    {"is_int",          &Builtin{Fn: BuiltinFuncIsInt},},
+    {"is_string",       &Builtin{Fn: BuiltinFuncIsString},},
+    {"is_array",        &Builtin{Fn: BuiltinFuncIsArray},},
+    {"is_hash",         &Builtin{Fn: BuiltinFuncIsHash},},
+    {"is_fn",           &Builtin{Fn: BuiltinFuncIsFn},},
+    {"int",             &Builtin{Fn: BuiltinFuncInt},},
+    {"str",             &Builtin{Fn: BuiltinFuncStr},},
+    {"bool",            &Builtin{Fn: BuiltinFuncBool},},
+    {"abs",             &Builtin{Fn: BuiltinFuncAbs},},
+    {"min",             &Builtin{Fn: BuiltinFuncMin},},
+    {"max",             &Builtin{Fn: BuiltinFuncMax},},
+    {"pow",             &Builtin{Fn: BuiltinFuncPow},},
+    {"sqrt",            &Builtin{Fn: BuiltinFuncSqrt},},
+    {"random",          &Builtin{Fn: BuiltinFuncRandom},},
+    {"read_file",       &Builtin{Fn: BuiltinFuncReadFile},},
+    {"write_file",      &Builtin{Fn: BuiltinFuncWriteFile},},
+    {"list_dir",        &Builtin{Fn: BuiltinFuncListDir},},
+    {"exists",          &Builtin{Fn: BuiltinFuncExists},},
+    {"json_encode",     &Builtin{Fn: BuiltinFuncJsonEncode},},
+    {"json_decode",     &Builtin{Fn: BuiltinFuncJsonDecode},},
+    {"regex",           &Builtin{Fn: BuiltinFuncRegex},},
+    {"match",           &Builtin{Fn: BuiltinFuncMatch},},
+    {"find_all",        &Builtin{Fn: BuiltinFuncFindAll},},
+    {"captures",        &Builtin{Fn: BuiltinFuncCaptures},},
+    {"replace",         &Builtin{Fn: BuiltinFuncReplace},},
+    {"split",           &Builtin{Fn: BuiltinFuncSplit},},
+    {"now",             &Builtin{Fn: BuiltinFuncNow},},
+    {"sleep",           &Builtin{Fn: BuiltinFuncSleep},},
+    {"format_time",     &Builtin{Fn: BuiltinFuncFormatTime},},
+    {"parse_time",      &Builtin{Fn: BuiltinFuncParseTime},},
+    {"duration",        &Builtin{Fn: BuiltinFuncDuration},},
+    {"format_duration", &Builtin{Fn: BuiltinFuncFormatDuration},},
+    {"eputs",           &Builtin{Fn: BuiltinFuncEputs},},
+    {"print",           &Builtin{Fn: BuiltinFuncPrint},},
+    {"read_line",       &Builtin{Fn: BuiltinFuncReadLine},},
+    {"set",             &Builtin{Fn: BuiltinFuncSet},},
+    {"is_set",          &Builtin{Fn: BuiltinFuncIsSet},},
+    {"add",             &Builtin{Fn: BuiltinFuncAdd},},
+    {"remove",          &Builtin{Fn: BuiltinFuncRemove},},
+    {"contains",        &Builtin{Fn: BuiltinFuncContains},},
+    {"union",           &Builtin{Fn: BuiltinFuncUnion},},
+    {"intersection",    &Builtin{Fn: BuiltinFuncIntersection},},
+    {"difference",      &Builtin{Fn: BuiltinFuncDifference},},
+    {"to_array",        &Builtin{Fn: BuiltinFuncToArray},},
+    {"bytes",           &Builtin{Fn: BuiltinFuncBytes},},
+    {"is_bytes",        &Builtin{Fn: BuiltinFuncIsBytes},},
+    {"hex_encode",      &Builtin{Fn: BuiltinFuncHexEncode},},
+    {"hex_decode",      &Builtin{Fn: BuiltinFuncHexDecode},},
+    {"base64_encode",   &Builtin{Fn: BuiltinFuncBase64Encode},},
+    {"base64_decode",   &Builtin{Fn: BuiltinFuncBase64Decode},},
+    {"sha256",          &Builtin{Fn: BuiltinFuncSha256},},
+    {"sha1",            &Builtin{Fn: BuiltinFuncSha1},},
+    {"md5",             &Builtin{Fn: BuiltinFuncMd5},},
+    {"crc32",           &Builtin{Fn: BuiltinFuncCrc32},},
+    {"hmac",            &Builtin{Fn: BuiltinFuncHmac},},
+    {"args",            &Builtin{Fn: BuiltinFuncArgs},},
+    {"env",             &Builtin{Fn: BuiltinFuncEnv},},
+    {"exit",            &Builtin{Fn: BuiltinFuncExit},},
+    {"exec",            &Builtin{Fn: BuiltinFuncExec},},
+    {"http_get",        &Builtin{Fn: BuiltinFuncHttpGet},},
+    {"http_post",       &Builtin{Fn: BuiltinFuncHttpPost},},
+    {"http_request",    &Builtin{Fn: BuiltinFuncHttpRequest},},
+    {"serve",           &Builtin{Fn: BuiltinFuncServe},},
+    {"csv_parse",       &Builtin{Fn: BuiltinFuncCsvParse},},
+    {"csv_format",      &Builtin{Fn: BuiltinFuncCsvFormat},},
 }
 
 func GetBuiltinByName(name string) *Builtin {
@@ -24,22 +93,26 @@ func GetBuiltinByName(name string) *Builtin {
     return nil
 }
 
-func BuiltinFuncLen(args ...Object) Object {
+func BuiltinFuncLen(host *Host, args ...Object) Object {
     if len(args) != 1 {
         return newErrorObejct("wrong number of arguments. got=%d, want=1", len(args))
     }
 
     switch arg := args[0].(type) {
     case *String:
-        return &Integer{Value: int64(len(arg.Value))}
+        return NewInteger(int64(len(arg.Value)))
     case *Array:
-        return &Integer{Value: int64(len(arg.Elements))}
+        return NewInteger(int64(len(arg.Elements)))
+    case *Set:
+        return NewInteger(int64(len(arg.Elements)))
+    case *Bytes:
+        return NewInteger(int64(len(arg.Value)))
     default:
         return newErrorObejct("argument to `len` not supported, got %s", arg.Type())
     }
 }
 
-func BuiltinFuncFirst(args ...Object) Object {
+func BuiltinFuncFirst(host *Host, args ...Object) Object {
     if len(args) != 1 {
         return newErrorObejct("wrong number of arguments. got=%d, want=1", len(args))
     }
@@ -56,7 +129,7 @@ func BuiltinFuncFirst(args ...Object) Object {
     }
 }
 
-func BuiltinFuncLast(args ...Object) Object {
+func BuiltinFuncLast(host *Host, args ...Object) Object {
     if len(args) != 1 {
         return newErrorObejct("wrong number of arguments. got=%d, want=1", len(args))
     }
@@ -74,7 +147,7 @@ func BuiltinFuncLast(args ...Object) Object {
     }
 }
 
-func BuiltinFuncRest(args ...Object) Object {
+func BuiltinFuncRest(host *Host, args ...Object) Object {
     if len(args) != 1 {
         return newErrorObejct("wrong number of arguments. got=%d, want=1", len(args))
     }
@@ -94,7 +167,7 @@ func BuiltinFuncRest(args ...Object) Object {
     }
 }
 
-func BuiltinFuncPush(args ...Object) Object {
+func BuiltinFuncPush(host *Host, args ...Object) Object {
     if len(args) != 2 {
         return newErrorObejct("wrong number of arguments. got=%d, want=2", len(args))
     }
@@ -112,14 +185,88 @@ func BuiltinFuncPush(args ...Object) Object {
     }
 }
 
-func BuiltinFuncPuts(args ...Object) Object {
+func BuiltinFuncPuts(host *Host, args ...Object) Object {
     for _, arg := range args {
-        fmt.Println(arg.Inspect())
+        fmt.Fprintln(host.stdout(), arg.Inspect())
     }
 
     return nil 
 }
 
+func BuiltinFuncEputs(host *Host, args ...Object) Object {
+    for _, arg := range args {
+        fmt.Fprintln(host.stderr(), arg.Inspect())
+    }
+
+    return nil
+}
+
+func BuiltinFuncPrint(host *Host, args ...Object) Object {
+    for _, arg := range args {
+        fmt.Fprint(host.stdout(), arg.Inspect())
+    }
+
+    return nil
+}
+
+// read_line() returns the next line of input, or null at the end of it
+func BuiltinFuncReadLine(host *Host, args ...Object) Object {
+    if len(args) != 0 {
+        return newErrorObejct("wrong number of arguments. got=%d, want=0", len(args))
+    }
+
+    line, err := host.ReadLine()
+    if err == io.EOF {
+        return nil
+    }
+    if err != nil {
+        return newErrorObejct("read_line: %s", err)
+    }
+
+    return &String{Value: line}
+}
+
 func newErrorObejct(format string, a ...interface{}) *Error {
     return &Error{Message : fmt.Sprintf(format, a...)}
 }
+
+// options checks the options hash passed to the builtin name and returns
+// it keyed by option name. Keys must be STRING and one of known.
+func options(name string, arg Object, known ...string) (map[string]Object, *Error) {
+    hash, ok := arg.(*Hash)
+    if !ok {
+        return nil, newErrorObejct("options to `%s` must be HASH, got %s", name, arg.Type())
+    }
+
+    opts := make(map[string]Object, len(hash.Pairs))
+    for _, pair := range hash.Pairs {
+        key, ok := pair.Key.(*String)
+        if !ok {
+            return nil, newErrorObejct("%s: option names must be STRING, got %s",
+                name, pair.Key.Type())
+        }
+
+        found := false
+        for _, k := range known {
+            found = found || k == key.Value
+        }
+        if !found {
+            return nil, newErrorObejct("%s: unknown option %q", name, key.Value)
+        }
+
+        opts[key.Value] = pair.Value
+    }
+
+    return opts, nil
+}
+
+// stringHash builds a hash with STRING keys
+func stringHash(fields map[string]Object) *Hash {
+    pairs := make(map[HashKey]HashPair, len(fields))
+    for k, v := range fields {
+        key := &String{Value: k}
+        pairs[key.HashKey()] = HashPair{Key: key, Value: v}
+    }
+
+    return &Hash{Pairs: pairs}
+}
@@ -0,0 +1,114 @@
+package object
+
+import (
+    "encoding/base64"
+    "encoding/hex"
+)
+
+// bytes("abc")        => the bytes of a string
+// bytes([104, 105])   => an array of integers in [0, 255]
+func BuiltinFuncBytes(host *Host, args ...Object) Object {
+    if len(args) != 1 {
+        return newErrorObejct("wrong number of arguments. got=%d, want=1", len(args))
+    }
+
+    switch arg := args[0].(type) {
+    case *Bytes:
+        return arg
+    case *String:
+        return &Bytes{Value: []byte(arg.Value)}
+    case *Array:
+        value := make([]byte, len(arg.Elements))
+        for i, e := range arg.Elements {
+            integer, ok := e.(*Integer)
+            if !ok || integer.Value < 0 || integer.Value > 255 {
+                return newErrorObejct("byte out of range: %s", e.Inspect())
+            }
+            value[i] = byte(integer.Value)
+        }
+        return &Bytes{Value: value}
+    default:
+        return newErrorObejct("argument to `bytes` not supported, got %s", arg.Type())
+    }
+}
+
+func BuiltinFuncIsBytes(host *Host, args ...Object) Object {
+    return isType(args, BYTES_OBJ)
+}
+
+func BuiltinFuncHexEncode(host *Host, args ...Object) Object {
+    data, errObj := binaryArg("hex_encode", args)
+    if errObj != nil {
+        return errObj
+    }
+
+    return &String{Value: hex.EncodeToString(data)}
+}
+
+func BuiltinFuncHexDecode(host *Host, args ...Object) Object {
+    str, errObj := encodedArg("hex_decode", args)
+    if errObj != nil {
+        return errObj
+    }
+
+    value, err := hex.DecodeString(str)
+    if err != nil {
+        return newErrorObejct("hex_decode: %s", err)
+    }
+
+    return &Bytes{Value: value}
+}
+
+func BuiltinFuncBase64Encode(host *Host, args ...Object) Object {
+    data, errObj := binaryArg("base64_encode", args)
+    if errObj != nil {
+        return errObj
+    }
+
+    return &String{Value: base64.StdEncoding.EncodeToString(data)}
+}
+
+func BuiltinFuncBase64Decode(host *Host, args ...Object) Object {
+    str, errObj := encodedArg("base64_decode", args)
+    if errObj != nil {
+        return errObj
+    }
+
+    value, err := base64.StdEncoding.DecodeString(str)
+    if err != nil {
+        return newErrorObejct("base64_decode: %s", err)
+    }
+
+    return &Bytes{Value: value}
+}
+
+// the encoders take either BYTES or the raw bytes of a STRING
+func binaryArg(name string, args []Object) ([]byte, *Error) {
+    if len(args) != 1 {
+        return nil, newErrorObejct("wrong number of arguments. got=%d, want=1", len(args))
+    }
+
+    switch arg := args[0].(type) {
+    case *Bytes:
+        return arg.Value, nil
+    case *String:
+        return []byte(arg.Value), nil
+    default:
+        return nil, newErrorObejct("argument to `%s` must be BYTES or STRING, got %s",
+            name, arg.Type())
+    }
+}
+
+func encodedArg(name string, args []Object) (string, *Error) {
+    if len(args) != 1 {
+        return "", newErrorObejct("wrong number of arguments. got=%d, want=1", len(args))
+    }
+
+    str, ok := args[0].(*String)
+    if !ok {
+        return "", newErrorObejct("argument to `%s` must be STRING, got %s",
+            name, args[0].Type())
+    }
+
+    return str.Value, nil
+}
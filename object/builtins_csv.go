@@ -0,0 +1,229 @@
+package object
+
+import (
+    "encoding/csv"
+    "sort"
+    "strings"
+    "unicode/utf8"
+)
+
+// csv_parse(text)                    => an array of rows, each an array of STRING
+// csv_parse(text, {"header": true})  => an array of hashes keyed by the first row
+// the "delimiter" option replaces the comma
+func BuiltinFuncCsvParse(host *Host, args ...Object) Object {
+    if len(args) < 1 || len(args) > 2 {
+        return newErrorObejct("wrong number of arguments. got=%d, want=1..2", len(args))
+    }
+
+    text, ok := args[0].(*String)
+    if !ok {
+        return newErrorObejct("first argument to `csv_parse` must be STRING, got %s", args[0].Type())
+    }
+
+    opts := map[string]Object{}
+    if len(args) > 1 {
+        var errObj *Error
+        opts, errObj = options("csv_parse", args[1], "header", "delimiter")
+        if errObj != nil {
+            return errObj
+        }
+    }
+
+    r := csv.NewReader(strings.NewReader(text.Value))
+    if errObj := csvDelimiter("csv_parse", opts, &r.Comma); errObj != nil {
+        return errObj
+    }
+
+    header := false
+    if h, ok := opts["header"]; ok {
+        b, ok := h.(*Boolean)
+        if !ok {
+            return newErrorObejct("csv_parse: header must be BOOLEAN, got %s", h.Type())
+        }
+        header = b.Value
+    }
+
+    records, err := r.ReadAll()
+    if err != nil {
+        return newErrorObejct("csv_parse: %s", err)
+    }
+
+    if !header {
+        rows := make([]Object, len(records))
+        for i, record := range records {
+            rows[i] = stringArray(record)
+        }
+        return &Array{Elements: rows}
+    }
+
+    if len(records) == 0 {
+        return &Array{Elements: []Object{}}
+    }
+
+    names := records[0]
+    rows  := make([]Object, len(records) - 1)
+    for i, record := range records[1:] {
+        fields := make(map[string]Object, len(names))
+        for j, name := range names {
+            fields[name] = &String{Value: record[j]}
+        }
+        rows[i] = stringHash(fields)
+    }
+
+    return &Array{Elements: rows}
+}
+
+// csv_format(rows) takes an array of arrays, or of hashes in which case a
+// header row is written first. The "header" option lists the columns and
+// their order, which otherwise are the sorted keys of the first hash. The
+// "delimiter" option replaces the comma.
+func BuiltinFuncCsvFormat(host *Host, args ...Object) Object {
+    if len(args) < 1 || len(args) > 2 {
+        return newErrorObejct("wrong number of arguments. got=%d, want=1..2", len(args))
+    }
+
+    rows, ok := args[0].(*Array)
+    if !ok {
+        return newErrorObejct("first argument to `csv_format` must be ARRAY, got %s", args[0].Type())
+    }
+
+    opts := map[string]Object{}
+    if len(args) > 1 {
+        var errObj *Error
+        opts, errObj = options("csv_format", args[1], "header", "delimiter")
+        if errObj != nil {
+            return errObj
+        }
+    }
+
+    var out strings.Builder
+    w := csv.NewWriter(&out)
+    if errObj := csvDelimiter("csv_format", opts, &w.Comma); errObj != nil {
+        return errObj
+    }
+
+    var names []string
+    if h, ok := opts["header"]; ok {
+        array, ok := h.(*Array)
+        if !ok {
+            return newErrorObejct("csv_format: header must be ARRAY, got %s", h.Type())
+        }
+
+        record, errObj := csvRecord(array.Elements)
+        if errObj != nil {
+            return errObj
+        }
+        names = record
+    } else if len(rows.Elements) > 0 {
+        if first, ok := rows.Elements[0].(*Hash); ok {
+            for _, pair := range first.Pairs {
+                key, ok := pair.Key.(*String)
+                if !ok {
+                    return newErrorObejct("csv_format: hash keys must be STRING, got %s",
+                        pair.Key.Type())
+                }
+                names = append(names, key.Value)
+            }
+            sort.Strings(names)
+        }
+    }
+
+    if names != nil {
+        if err := w.Write(names); err != nil {
+            return newErrorObejct("csv_format: %s", err)
+        }
+    }
+
+    for _, row := range rows.Elements {
+        var cells []Object
+
+        switch row := row.(type) {
+        case *Array:
+            cells = row.Elements
+        case *Hash:
+            for _, pair := range row.Pairs {
+                if _, ok := pair.Key.(*String); !ok {
+                    return newErrorObejct("csv_format: hash keys must be STRING, got %s",
+                        pair.Key.Type())
+                }
+            }
+
+            cells = make([]Object, len(names))
+            for i, name := range names {
+                key := &String{Value: name}
+                if pair, ok := row.Pairs[key.HashKey()]; ok {
+                    cells[i] = pair.Value
+                } else {
+                    cells[i] = NULL
+                }
+            }
+        default:
+            return newErrorObejct("csv_format: rows must be ARRAY or HASH, got %s", row.Type())
+        }
+
+        record, errObj := csvRecord(cells)
+        if errObj != nil {
+            return errObj
+        }
+        if err := w.Write(record); err != nil {
+            return newErrorObejct("csv_format: %s", err)
+        }
+    }
+
+    w.Flush()
+    if err := w.Error(); err != nil {
+        return newErrorObejct("csv_format: %s", err)
+    }
+
+    return &String{Value: out.String()}
+}
+
+func csvDelimiter(name string, opts map[string]Object, comma *rune) *Error {
+    d, ok := opts["delimiter"]
+    if !ok {
+        return nil
+    }
+
+    str, ok := d.(*String)
+    if !ok || utf8.RuneCountInString(str.Value) != 1 {
+        return newErrorObejct("%s: delimiter must be a single character, got %s",
+            name, d.Inspect())
+    }
+
+    // the characters encoding/csv rejects, the writer only says so on Write
+    r, _ := utf8.DecodeRuneInString(str.Value)
+    if r == '"' || r == '\r' || r == '\n' || r == utf8.RuneError {
+        return newErrorObejct("%s: invalid delimiter %q", name, str.Value)
+    }
+
+    *comma, _ = utf8.DecodeRuneInString(str.Value)
+    return nil
+}
+
+// cells are written as they would print, null as an empty cell
+func csvRecord(cells []Object) ([]string, *Error) {
+    record := make([]string, len(cells))
+    for i, cell := range cells {
+        switch cell := cell.(type) {
+        case *String:
+            record[i] = cell.Value
+        case *Integer, *Boolean:
+            record[i] = cell.Inspect()
+        case *Null:
+            record[i] = ""
+        default:
+            return nil, newErrorObejct("csv_format: unsupported cell %s", cell.Type())
+        }
+    }
+
+    return record, nil
+}
+
+func stringArray(values []string) *Array {
+    elements := make([]Object, len(values))
+    for i, v := range values {
+        elements[i] = &String{Value: v}
+    }
+
+    return &Array{Elements: elements}
+}
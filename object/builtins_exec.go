@@ -0,0 +1,133 @@
+package object
+
+import (
+    "bytes"
+    "context"
+    "errors"
+    "os/exec"
+    "strings"
+    "time"
+)
+
+// how long a program may run when exec is given no "timeout"
+const defaultExecTimeout = 30 * time.Second
+
+// exec("git", ["status"], {"timeout": 1000, "dir": "src", "stdin": "..."})
+// runs a program the host allows and returns
+// {"stdout": ..., "stderr": ..., "status": ..., "timed_out": ...}
+//
+// With a DirFileSystem as Host.FS the program runs in its root, and "dir"
+// names a directory below it. Without one it runs in the working directory
+// of the process and "dir" is refused.
+func BuiltinFuncExec(host *Host, args ...Object) Object {
+    if len(args) < 1 || len(args) > 3 {
+        return newErrorObejct("wrong number of arguments. got=%d, want=1..3", len(args))
+    }
+
+    name, ok := args[0].(*String)
+    if !ok {
+        return newErrorObejct("first argument to `exec` must be STRING, got %s", args[0].Type())
+    }
+
+    if !commandAllowed(host, name.Value) {
+        return newErrorObejct("exec: command %q not allowed by host", name.Value)
+    }
+
+    var cmdArgs []string
+    if len(args) > 1 {
+        array, ok := args[1].(*Array)
+        if !ok {
+            return newErrorObejct("second argument to `exec` must be ARRAY, got %s", args[1].Type())
+        }
+
+        for _, e := range array.Elements {
+            arg, ok := e.(*String)
+            if !ok {
+                return newErrorObejct("exec: arguments must be STRING, got %s", e.Type())
+            }
+            cmdArgs = append(cmdArgs, arg.Value)
+        }
+    }
+
+    opts := map[string]Object{}
+    if len(args) > 2 {
+        var errObj *Error
+        opts, errObj = options("exec", args[2], "timeout", "dir", "stdin")
+        if errObj != nil {
+            return errObj
+        }
+    }
+
+    timeout := defaultExecTimeout
+    if t, ok := opts["timeout"]; ok {
+        ms, ok := t.(*Integer)
+        if !ok {
+            return newErrorObejct("exec: timeout must be INTEGER, got %s", t.Type())
+        }
+        timeout = time.Duration(ms.Value) * time.Millisecond
+    }
+
+    ctx, cancel := context.WithTimeout(context.Background(), timeout)
+    defer cancel()
+
+    cmd := exec.CommandContext(ctx, name.Value, cmdArgs...)
+    // don't wait on children that hold on to the output after a timeout
+    cmd.WaitDelay = 100 * time.Millisecond
+
+    var stdout, stderr bytes.Buffer
+    cmd.Stdout = &stdout
+    cmd.Stderr = &stderr
+
+    root, hasRoot := host.FS.(*DirFileSystem)
+    if hasRoot {
+        cmd.Dir = root.root.Name()
+    }
+
+    if dir, ok := opts["dir"]; ok {
+        str, ok := dir.(*String)
+        if !ok {
+            return newErrorObejct("exec: dir must be STRING, got %s", dir.Type())
+        }
+        if !hasRoot {
+            return newErrorObejct("exec: dir needs a host file system")
+        }
+
+        path, err := root.dirPath(str.Value)
+        if err != nil {
+            return newErrorObejct("exec: %s", err)
+        }
+        cmd.Dir = path
+    }
+
+    if stdin, ok := opts["stdin"]; ok {
+        str, ok := stdin.(*String)
+        if !ok {
+            return newErrorObejct("exec: stdin must be STRING, got %s", stdin.Type())
+        }
+        cmd.Stdin = strings.NewReader(str.Value)
+    }
+
+    // a non-zero status is a result, not an error
+    err := cmd.Run()
+    var exitErr *exec.ExitError
+    if err != nil && !errors.As(err, &exitErr) {
+        return newErrorObejct("exec: %s", err)
+    }
+
+    return stringHash(map[string]Object{
+        "stdout":    &String{Value: stdout.String()},
+        "stderr":    &String{Value: stderr.String()},
+        "status":    NewInteger(int64(cmd.ProcessState.ExitCode())),
+        "timed_out": nativeBoolToBooleanObject(ctx.Err() == context.DeadlineExceeded),
+    })
+}
+
+func commandAllowed(host *Host, name string) bool {
+    for _, allowed := range host.AllowedCommands {
+        if allowed == name {
+            return true
+        }
+    }
+
+    return false
+}
@@ -0,0 +1,85 @@
+package object
+
+import (
+    "crypto/hmac"
+    "crypto/md5"
+    "crypto/sha1"
+    "crypto/sha256"
+    "encoding/hex"
+    "fmt"
+    "hash"
+    "hash/crc32"
+)
+
+// the digests accepted by hmac, keyed by the name of their builtin
+var hashFuncs = map[string]func() hash.Hash {
+    "sha256": sha256.New,
+    "sha1":   sha1.New,
+    "md5":    md5.New,
+}
+
+func BuiltinFuncSha256(host *Host, args ...Object) Object {
+    return digest("sha256", args)
+}
+
+func BuiltinFuncSha1(host *Host, args ...Object) Object {
+    return digest("sha1", args)
+}
+
+func BuiltinFuncMd5(host *Host, args ...Object) Object {
+    return digest("md5", args)
+}
+
+// the IEEE checksum as 8 hex digits
+func BuiltinFuncCrc32(host *Host, args ...Object) Object {
+    data, errObj := binaryArg("crc32", args)
+    if errObj != nil {
+        return errObj
+    }
+
+    return &String{Value: fmt.Sprintf("%08x", crc32.ChecksumIEEE(data))}
+}
+
+// hmac("sha256", key, message)
+func BuiltinFuncHmac(host *Host, args ...Object) Object {
+    if len(args) != 3 {
+        return newErrorObejct("wrong number of arguments. got=%d, want=3", len(args))
+    }
+
+    algo, ok := args[0].(*String)
+    if !ok {
+        return newErrorObejct("first argument to `hmac` must be STRING, got %s", args[0].Type())
+    }
+
+    newHash, ok := hashFuncs[algo.Value]
+    if !ok {
+        return newErrorObejct("hmac: unknown algorithm %q", algo.Value)
+    }
+
+    key, errObj := binaryArg("hmac", args[1:2])
+    if errObj != nil {
+        return errObj
+    }
+
+    message, errObj := binaryArg("hmac", args[2:])
+    if errObj != nil {
+        return errObj
+    }
+
+    mac := hmac.New(newHash, key)
+    mac.Write(message)
+
+    return &String{Value: hex.EncodeToString(mac.Sum(nil))}
+}
+
+func digest(name string, args []Object) Object {
+    data, errObj := binaryArg(name, args)
+    if errObj != nil {
+        return errObj
+    }
+
+    h := hashFuncs[name]()
+    h.Write(data)
+
+    return &String{Value: hex.EncodeToString(h.Sum(nil))}
+}
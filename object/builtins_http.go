@@ -0,0 +1,149 @@
+package object
+
+import (
+    "context"
+    "io"
+    "net/http"
+    "strings"
+    "time"
+)
+
+// how long a request may take unless the "timeout" option says otherwise
+const defaultHTTPTimeout = 30 * time.Second
+
+// http_get(url[, opts])
+func BuiltinFuncHttpGet(host *Host, args ...Object) Object {
+    if len(args) < 1 || len(args) > 2 {
+        return newErrorObejct("wrong number of arguments. got=%d, want=1..2", len(args))
+    }
+
+    return httpRequest(host, "http_get", &String{Value: http.MethodGet}, args[0], args[1:])
+}
+
+// http_post(url, body[, opts])
+func BuiltinFuncHttpPost(host *Host, args ...Object) Object {
+    if len(args) < 2 || len(args) > 3 {
+        return newErrorObejct("wrong number of arguments. got=%d, want=2..3", len(args))
+    }
+
+    body, ok := args[1].(*String)
+    if !ok {
+        return newErrorObejct("second argument to `http_post` must be STRING, got %s", args[1].Type())
+    }
+
+    opts := map[string]Object{}
+    if len(args) > 2 {
+        var errObj *Error
+        opts, errObj = options("http_post", args[2], "headers", "timeout")
+        if errObj != nil {
+            return errObj
+        }
+    }
+    opts["body"] = body
+
+    return httpRequest(host, "http_post", &String{Value: http.MethodPost}, args[0],
+        []Object{stringHash(opts)})
+}
+
+// http_request(method, url[, {"headers": {...}, "body": "...", "timeout": ms}])
+func BuiltinFuncHttpRequest(host *Host, args ...Object) Object {
+    if len(args) < 2 || len(args) > 3 {
+        return newErrorObejct("wrong number of arguments. got=%d, want=2..3", len(args))
+    }
+
+    return httpRequest(host, "http_request", args[0], args[1], args[2:])
+}
+
+// sends the request and returns {"status": ..., "headers": ..., "body": ...},
+// whatever the status is
+func httpRequest(host *Host, name string, method, url Object, rest []Object) Object {
+    methodStr, ok1 := method.(*String)
+    urlStr, ok2    := url.(*String)
+    if !ok1 || !ok2 {
+        return newErrorObejct("method and url given to `%s` must be STRING, got %s and %s",
+            name, method.Type(), url.Type())
+    }
+
+    if host.NetworkDisabled {
+        return newErrorObejct("%s: network access disabled by host", name)
+    }
+
+    opts := map[string]Object{}
+    if len(rest) > 0 {
+        var errObj *Error
+        opts, errObj = options(name, rest[0], "headers", "body", "timeout")
+        if errObj != nil {
+            return errObj
+        }
+    }
+
+    timeout := defaultHTTPTimeout
+    if t, ok := opts["timeout"]; ok {
+        ms, ok := t.(*Integer)
+        if !ok {
+            return newErrorObejct("%s: timeout must be INTEGER, got %s", name, t.Type())
+        }
+        timeout = time.Duration(ms.Value) * time.Millisecond
+    }
+
+    var body io.Reader
+    if b, ok := opts["body"]; ok {
+        str, ok := b.(*String)
+        if !ok {
+            return newErrorObejct("%s: body must be STRING, got %s", name, b.Type())
+        }
+        body = strings.NewReader(str.Value)
+    }
+
+    ctx, cancel := context.WithTimeout(context.Background(), timeout)
+    defer cancel()
+
+    req, err := http.NewRequestWithContext(ctx, methodStr.Value, urlStr.Value, body)
+    if err != nil {
+        return newErrorObejct("%s: %s", name, err)
+    }
+
+    if h, ok := opts["headers"]; ok {
+        hash, ok := h.(*Hash)
+        if !ok {
+            return newErrorObejct("%s: headers must be HASH, got %s", name, h.Type())
+        }
+
+        for _, pair := range hash.Pairs {
+            k, ok1 := pair.Key.(*String)
+            v, ok2 := pair.Value.(*String)
+            if !ok1 || !ok2 {
+                return newErrorObejct("%s: headers must map STRING to STRING, got %s to %s",
+                    name, pair.Key.Type(), pair.Value.Type())
+            }
+            req.Header.Set(k.Value, v.Value)
+        }
+    }
+
+    client := host.HTTPClient
+    if client == nil {
+        client = http.DefaultClient
+    }
+
+    resp, err := client.Do(req)
+    if err != nil {
+        return newErrorObejct("%s: %s", name, err)
+    }
+    defer resp.Body.Close()
+
+    data, err := io.ReadAll(resp.Body)
+    if err != nil {
+        return newErrorObejct("%s: %s", name, err)
+    }
+
+    headers := make(map[string]Object, len(resp.Header))
+    for k, v := range resp.Header {
+        headers[k] = &String{Value: strings.Join(v, ", ")}
+    }
+
+    return stringHash(map[string]Object{
+        "status":  NewInteger(int64(resp.StatusCode)),
+        "headers": stringHash(headers),
+        "body":    &String{Value: string(data)},
+    })
+}
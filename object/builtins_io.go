@@ -0,0 +1,100 @@
+package object
+
+func BuiltinFuncReadFile(host *Host, args ...Object) Object {
+    if len(args) != 1 {
+        return newErrorObejct("wrong number of arguments. got=%d, want=1", len(args))
+    }
+
+    path, ok := args[0].(*String)
+    if !ok {
+        return newErrorObejct("argument to `read_file` must be STRING, got %s", args[0].Type())
+    }
+
+    if host.FS == nil {
+        return fileAccessDenied("read_file")
+    }
+
+    data, err := host.FS.ReadFile(path.Value)
+    if err != nil {
+        return newErrorObejct("read_file: %s", err)
+    }
+
+    return &String{Value: string(data)}
+}
+
+func BuiltinFuncWriteFile(host *Host, args ...Object) Object {
+    if len(args) != 2 {
+        return newErrorObejct("wrong number of arguments. got=%d, want=2", len(args))
+    }
+
+    path, ok1 := args[0].(*String)
+    data, ok2 := args[1].(*String)
+    if !ok1 || !ok2 {
+        return newErrorObejct("arguments to `write_file` must be STRING, got %s and %s",
+            args[0].Type(), args[1].Type())
+    }
+
+    if host.FS == nil {
+        return fileAccessDenied("write_file")
+    }
+
+    err := host.FS.WriteFile(path.Value, []byte(data.Value))
+    if err != nil {
+        return newErrorObejct("write_file: %s", err)
+    }
+
+    return nil
+}
+
+func BuiltinFuncListDir(host *Host, args ...Object) Object {
+    if len(args) != 1 {
+        return newErrorObejct("wrong number of arguments. got=%d, want=1", len(args))
+    }
+
+    path, ok := args[0].(*String)
+    if !ok {
+        return newErrorObejct("argument to `list_dir` must be STRING, got %s", args[0].Type())
+    }
+
+    if host.FS == nil {
+        return fileAccessDenied("list_dir")
+    }
+
+    names, err := host.FS.ReadDir(path.Value)
+    if err != nil {
+        return newErrorObejct("list_dir: %s", err)
+    }
+
+    elements := make([]Object, len(names))
+    for i, name := range names {
+        elements[i] = &String{Value: name}
+    }
+
+    return &Array{Elements: elements}
+}
+
+func BuiltinFuncExists(host *Host, args ...Object) Object {
+    if len(args) != 1 {
+        return newErrorObejct("wrong number of arguments. got=%d, want=1", len(args))
+    }
+
+    path, ok := args[0].(*String)
+    if !ok {
+        return newErrorObejct("argument to `exists` must be STRING, got %s", args[0].Type())
+    }
+
+    if host.FS == nil {
+        return fileAccessDenied("exists")
+    }
+
+    found, err := host.FS.Exists(path.Value)
+    if err != nil {
+        return newErrorObejct("exists: %s", err)
+    }
+
+    return nativeBoolToBooleanObject(found)
+}
+
+func fileAccessDenied(name string) *Error {
+    return newErrorObejct("%s: file access denied by host", name)
+}
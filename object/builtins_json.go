@@ -0,0 +1,160 @@
+package object
+
+import (
+    "bytes"
+    "encoding/json"
+    "fmt"
+    "strings"
+)
+
+// json_encode(value)          => compact JSON
+// json_encode(value, true)    => JSON indented by two spaces
+// json_encode(value, "  ")    => JSON indented by the given string
+func BuiltinFuncJsonEncode(host *Host, args ...Object) Object {
+    if len(args) != 1 && len(args) != 2 {
+        return newErrorObejct("wrong number of arguments. got=%d, want=1 or 2", len(args))
+    }
+
+    indent := ""
+    if len(args) == 2 {
+        switch opt := args[1].(type) {
+        case *Boolean:
+            if opt.Value {
+                indent = "  "
+            }
+        case *String:
+            indent = opt.Value
+        default:
+            return newErrorObejct("pretty option to `json_encode` must be BOOLEAN or STRING, got %s",
+                opt.Type())
+        }
+    }
+
+    value, err := toJSONValue(args[0])
+    if err != nil {
+        return newErrorObejct("json_encode: %s", err)
+    }
+
+    var out bytes.Buffer
+    enc := json.NewEncoder(&out)
+    enc.SetEscapeHTML(false)
+    enc.SetIndent("", indent)
+
+    // maps are encoded with their keys sorted, so the output is deterministic
+    if err := enc.Encode(value); err != nil {
+        return newErrorObejct("json_encode: %s", err)
+    }
+
+    return &String{Value: strings.TrimSuffix(out.String(), "\n")}
+}
+
+func BuiltinFuncJsonDecode(host *Host, args ...Object) Object {
+    if len(args) != 1 {
+        return newErrorObejct("wrong number of arguments. got=%d, want=1", len(args))
+    }
+
+    str, ok := args[0].(*String)
+    if !ok {
+        return newErrorObejct("argument to `json_decode` must be STRING, got %s", args[0].Type())
+    }
+
+    dec := json.NewDecoder(strings.NewReader(str.Value))
+    dec.UseNumber()
+
+    var value interface{}
+    if err := dec.Decode(&value); err != nil {
+        return newErrorObejct("json_decode: %s", err)
+    }
+    if dec.More() {
+        return newErrorObejct("json_decode: unexpected data after top-level value")
+    }
+
+    obj, err := fromJSONValue(value)
+    if err != nil {
+        return newErrorObejct("json_decode: %s", err)
+    }
+
+    return obj
+}
+
+func toJSONValue(obj Object) (interface{}, error) {
+    switch obj := obj.(type) {
+    case *Null:
+        return nil, nil
+    case *Integer:
+        return obj.Value, nil
+    case *String:
+        return obj.Value, nil
+    case *Boolean:
+        return obj.Value, nil
+    case *Array:
+        elements := make([]interface{}, len(obj.Elements))
+        for i, e := range obj.Elements {
+            value, err := toJSONValue(e)
+            if err != nil {
+                return nil, err
+            }
+            elements[i] = value
+        }
+        return elements, nil
+    case *Hash:
+        pairs := make(map[string]interface{}, len(obj.Pairs))
+        for _, pair := range obj.Pairs {
+            key, ok := pair.Key.(*String)
+            if !ok {
+                return nil, fmt.Errorf("unsupported hash key %s, want STRING",
+                    pair.Key.Type())
+            }
+
+            value, err := toJSONValue(pair.Value)
+            if err != nil {
+                return nil, err
+            }
+            pairs[key.Value] = value
+        }
+        return pairs, nil
+    default:
+        return nil, fmt.Errorf("unsupported value %s", obj.Type())
+    }
+}
+
+func fromJSONValue(value interface{}) (Object, error) {
+    switch value := value.(type) {
+    case nil:
+        return NULL, nil
+    case bool:
+        return nativeBoolToBooleanObject(value), nil
+    case string:
+        return &String{Value: value}, nil
+    case json.Number:
+        integer, err := value.Int64()
+        if err != nil {
+            return nil, fmt.Errorf("number %s is not an integer", value)
+        }
+        return NewInteger(integer), nil
+    case []interface{}:
+        elements := make([]Object, len(value))
+        for i, e := range value {
+            obj, err := fromJSONValue(e)
+            if err != nil {
+                return nil, err
+            }
+            elements[i] = obj
+        }
+        return &Array{Elements: elements}, nil
+    case map[string]interface{}:
+        pairs := make(map[HashKey]HashPair, len(value))
+        for k, v := range value {
+            obj, err := fromJSONValue(v)
+            if err != nil {
+                return nil, err
+            }
+
+            key := &String{Value: k}
+            pairs[key.HashKey()] = HashPair{Key: key, Value: obj}
+        }
+        return &Hash{Pairs: pairs}, nil
+    default:
+        return nil, fmt.Errorf("unexpected JSON value %T", value)
+    }
+}
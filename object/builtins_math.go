@@ -0,0 +1,159 @@
+package object
+
+import "math"
+
+func BuiltinFuncAbs(host *Host, args ...Object) Object {
+    if len(args) != 1 {
+        return newErrorObejct("wrong number of arguments. got=%d, want=1", len(args))
+    }
+
+    arg, ok := args[0].(*Integer)
+    if !ok {
+        return newErrorObejct("argument to `abs` must be INTEGER, got %s", args[0].Type())
+    }
+
+    if arg.Value == math.MinInt64 {
+        return newErrorObejct("integer overflow: abs(%d)", arg.Value)
+    }
+    if arg.Value < 0 {
+        return NewInteger(-arg.Value)
+    }
+    return arg
+}
+
+func BuiltinFuncMin(host *Host, args ...Object) Object {
+    return extremum("min", args, func(a, b int64) bool { return a < b })
+}
+
+func BuiltinFuncMax(host *Host, args ...Object) Object {
+    return extremum("max", args, func(a, b int64) bool { return a > b })
+}
+
+func BuiltinFuncPow(host *Host, args ...Object) Object {
+    if len(args) != 2 {
+        return newErrorObejct("wrong number of arguments. got=%d, want=2", len(args))
+    }
+
+    base, ok1 := args[0].(*Integer)
+    exp, ok2  := args[1].(*Integer)
+    if !ok1 || !ok2 {
+        return newErrorObejct("arguments to `pow` must be INTEGER, got %s and %s",
+            args[0].Type(), args[1].Type())
+    }
+
+    if exp.Value < 0 {
+        return newErrorObejct("negative exponent for `pow`: %d", exp.Value)
+    }
+
+    // exponentiation by squaring
+    result, b, e := int64(1), base.Value, exp.Value
+    for e > 0 {
+        if e & 1 == 1 {
+            result *= b
+        }
+        b *= b
+        e >>= 1
+    }
+
+    return NewInteger(result)
+}
+
+// integer square root, rounded down
+func BuiltinFuncSqrt(host *Host, args ...Object) Object {
+    if len(args) != 1 {
+        return newErrorObejct("wrong number of arguments. got=%d, want=1", len(args))
+    }
+
+    arg, ok := args[0].(*Integer)
+    if !ok {
+        return newErrorObejct("argument to `sqrt` must be INTEGER, got %s", args[0].Type())
+    }
+
+    if arg.Value < 0 {
+        return newErrorObejct("square root of negative number: %d", arg.Value)
+    }
+
+    // math.Sqrt may be off by one for large values, fix it up. The checks
+    // divide rather than square, which would overflow near MaxInt64
+    r := int64(math.Sqrt(float64(arg.Value)))
+    for r > 0 && r > arg.Value / r {
+        r--
+    }
+    for r + 1 <= arg.Value / (r + 1) {
+        r++
+    }
+
+    return NewInteger(r)
+}
+
+// random()          => a non-negative integer
+// random(n)         => an integer in [0, n)
+// random(low, high) => an integer in [low, high)
+func BuiltinFuncRandom(host *Host, args ...Object) Object {
+    bounds := make([]int64, len(args))
+    for i, arg := range args {
+        integer, ok := arg.(*Integer)
+        if !ok {
+            return newErrorObejct("arguments to `random` must be INTEGER, got %s", arg.Type())
+        }
+        bounds[i] = integer.Value
+    }
+
+    var low, high int64
+
+    r := host.random()
+    switch len(bounds) {
+    case 0:
+        return NewInteger(r.Int63())
+    case 1:
+        low, high = 0, bounds[0]
+    case 2:
+        low, high = bounds[0], bounds[1]
+    default:
+        return newErrorObejct("wrong number of arguments. got=%d, want=0..2", len(args))
+    }
+
+    if high <= low {
+        return newErrorObejct("empty range for `random`: [%d, %d)", low, high)
+    }
+
+    // high - low overflows int64 for ranges wider than MaxInt64, the
+    // difference is taken unsigned and drawn by rejection then
+    span := uint64(high) - uint64(low)
+    if span <= math.MaxInt64 {
+        return NewInteger(low + r.Int63n(int64(span)))
+    }
+
+    n := r.Uint64()
+    for n >= span {
+        n = r.Uint64()
+    }
+    return NewInteger(int64(uint64(low) + n))
+}
+
+// min and max accept either several integers or a single array of them
+func extremum(name string, args []Object, better func(a, b int64) bool) Object {
+    if len(args) == 1 {
+        if array, ok := args[0].(*Array); ok {
+            args = array.Elements
+        }
+    }
+
+    if len(args) == 0 {
+        return newErrorObejct("`%s` of no values", name)
+    }
+
+    var result *Integer
+    for _, arg := range args {
+        integer, ok := arg.(*Integer)
+        if !ok {
+            return newErrorObejct("arguments to `%s` must be INTEGER, got %s", name, arg.Type())
+        }
+
+        if result == nil || better(integer.Value, result.Value) {
+            result = integer
+        }
+    }
+
+    return result
+}
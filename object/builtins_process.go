@@ -0,0 +1,58 @@
+package object
+
+// the arguments the script was started with, not including its own path
+func BuiltinFuncArgs(host *Host, args ...Object) Object {
+    if len(args) != 0 {
+        return newErrorObejct("wrong number of arguments. got=%d, want=0", len(args))
+    }
+
+    elements := make([]Object, len(host.Args))
+    for i, arg := range host.Args {
+        elements[i] = &String{Value: arg}
+    }
+
+    return &Array{Elements: elements}
+}
+
+// env()       => a hash of all variables
+// env("HOME") => a single variable, or null if it is not set
+func BuiltinFuncEnv(host *Host, args ...Object) Object {
+    switch len(args) {
+    case 0:
+        pairs := make(map[HashKey]HashPair, len(host.Env))
+        for name, value := range host.Env {
+            key := &String{Value: name}
+            pairs[key.HashKey()] = HashPair{Key: key, Value: &String{Value: value}}
+        }
+        return &Hash{Pairs: pairs}
+    case 1:
+        name, ok := args[0].(*String)
+        if !ok {
+            return newErrorObejct("argument to `env` must be STRING, got %s", args[0].Type())
+        }
+
+        value, ok := host.Env[name.Value]
+        if !ok {
+            return nil
+        }
+        return &String{Value: value}
+    default:
+        return newErrorObejct("wrong number of arguments. got=%d, want=0 or 1", len(args))
+    }
+}
+
+// exit() ends the script with status 0, exit(code) with the given one
+func BuiltinFuncExit(host *Host, args ...Object) Object {
+    switch len(args) {
+    case 0:
+        return &Exit{Code: 0}
+    case 1:
+        code, ok := args[0].(*Integer)
+        if !ok {
+            return newErrorObejct("argument to `exit` must be INTEGER, got %s", args[0].Type())
+        }
+        return &Exit{Code: int(code.Value)}
+    default:
+        return newErrorObejct("wrong number of arguments. got=%d, want=0 or 1", len(args))
+    }
+}
@@ -0,0 +1,201 @@
+package object
+
+import (
+    "container/list"
+    "regexp"
+    "sync"
+)
+
+// how many compiled patterns regexCache keeps, patterns built from input,
+// say in a request handler, would make it grow without end otherwise
+const regexCacheSize = 256
+
+// compiled patterns are shared by every script in the process, so that a
+// pattern used inside a loop or a request handler is compiled only once.
+// Once full, the pattern used least recently makes room for a new one.
+var regexCache = struct {
+    sync.Mutex
+    patterns map[string]*list.Element
+    order    *list.List // of *Regex, most recently used first
+}{patterns: make(map[string]*list.Element), order: list.New()}
+
+func compileRegex(pattern string) (*Regex, error) {
+    regexCache.Lock()
+    defer regexCache.Unlock()
+
+    if e, ok := regexCache.patterns[pattern]; ok {
+        regexCache.order.MoveToFront(e)
+        return e.Value.(*Regex), nil
+    }
+
+    compiled, err := regexp.Compile(pattern)
+    if err != nil {
+        return nil, err
+    }
+
+    if regexCache.order.Len() >= regexCacheSize {
+        oldest := regexCache.order.Back()
+        regexCache.order.Remove(oldest)
+        delete(regexCache.patterns, oldest.Value.(*Regex).Regexp.String())
+    }
+
+    re := &Regex{Regexp: compiled}
+    regexCache.patterns[pattern] = regexCache.order.PushFront(re)
+    return re, nil
+}
+
+// regexArg accepts either a REGEX or a STRING holding the pattern
+func regexArg(name string, arg Object) (*Regex, *Error) {
+    switch arg := arg.(type) {
+    case *Regex:
+        return arg, nil
+    case *String:
+        re, err := compileRegex(arg.Value)
+        if err != nil {
+            return nil, newErrorObejct("%s: %s", name, err)
+        }
+        return re, nil
+    default:
+        return nil, newErrorObejct("pattern to `%s` must be REGEX or STRING, got %s",
+            name, arg.Type())
+    }
+}
+
+func BuiltinFuncRegex(host *Host, args ...Object) Object {
+    if len(args) != 1 {
+        return newErrorObejct("wrong number of arguments. got=%d, want=1", len(args))
+    }
+
+    re, errObj := regexArg("regex", args[0])
+    if errObj != nil {
+        return errObj
+    }
+
+    return re
+}
+
+// match(re, s) => [whole match, group 1, group 2, ...], or null when s
+// does not match. Groups that did not participate are null.
+func BuiltinFuncMatch(host *Host, args ...Object) Object {
+    re, str, errObj := regexAndString("match", args)
+    if errObj != nil {
+        return errObj
+    }
+
+    loc := re.Regexp.FindStringSubmatchIndex(str)
+    if loc == nil {
+        return nil
+    }
+
+    return submatchArray(str, loc)
+}
+
+// find_all(re, s) => an array holding one match array per match
+func BuiltinFuncFindAll(host *Host, args ...Object) Object {
+    re, str, errObj := regexAndString("find_all", args)
+    if errObj != nil {
+        return errObj
+    }
+
+    matches := []Object{}
+    for _, loc := range re.Regexp.FindAllStringSubmatchIndex(str, -1) {
+        matches = append(matches, submatchArray(str, loc))
+    }
+
+    return &Array{Elements: matches}
+}
+
+// captures(re, s) => {name: value} for every named group, or null when s
+// does not match
+func BuiltinFuncCaptures(host *Host, args ...Object) Object {
+    re, str, errObj := regexAndString("captures", args)
+    if errObj != nil {
+        return errObj
+    }
+
+    loc := re.Regexp.FindStringSubmatchIndex(str)
+    if loc == nil {
+        return nil
+    }
+
+    groups := submatchArray(str, loc).Elements
+    pairs  := make(map[HashKey]HashPair)
+    for i, name := range re.Regexp.SubexpNames() {
+        if name == "" {
+            continue
+        }
+
+        key := &String{Value: name}
+        pairs[key.HashKey()] = HashPair{Key: key, Value: groups[i]}
+    }
+
+    return &Hash{Pairs: pairs}
+}
+
+// replace(re, s, replacement) replaces every match; $1 and ${name} in the
+// replacement refer to groups
+func BuiltinFuncReplace(host *Host, args ...Object) Object {
+    if len(args) != 3 {
+        return newErrorObejct("wrong number of arguments. got=%d, want=3", len(args))
+    }
+
+    re, str, errObj := regexAndString("replace", args[:2])
+    if errObj != nil {
+        return errObj
+    }
+
+    repl, ok := args[2].(*String)
+    if !ok {
+        return newErrorObejct("replacement to `replace` must be STRING, got %s", args[2].Type())
+    }
+
+    return &String{Value: re.Regexp.ReplaceAllString(str, repl.Value)}
+}
+
+func BuiltinFuncSplit(host *Host, args ...Object) Object {
+    re, str, errObj := regexAndString("split", args)
+    if errObj != nil {
+        return errObj
+    }
+
+    parts    := re.Regexp.Split(str, -1)
+    elements := make([]Object, len(parts))
+    for i, p := range parts {
+        elements[i] = &String{Value: p}
+    }
+
+    return &Array{Elements: elements}
+}
+
+func regexAndString(name string, args []Object) (*Regex, string, *Error) {
+    if len(args) != 2 {
+        return nil, "", newErrorObejct("wrong number of arguments. got=%d, want=2", len(args))
+    }
+
+    re, errObj := regexArg(name, args[0])
+    if errObj != nil {
+        return nil, "", errObj
+    }
+
+    str, ok := args[1].(*String)
+    if !ok {
+        return nil, "", newErrorObejct("argument to `%s` must be STRING, got %s",
+            name, args[1].Type())
+    }
+
+    return re, str.Value, nil
+}
+
+func submatchArray(str string, loc []int) *Array {
+    groups := make([]Object, len(loc) / 2)
+    for i := range groups {
+        start, end := loc[2*i], loc[2*i+1]
+        if start < 0 {
+            groups[i] = NULL
+        } else {
+            groups[i] = &String{Value: str[start:end]}
+        }
+    }
+
+    return &Array{Elements: groups}
+}
@@ -0,0 +1,167 @@
+package object
+
+// set()        => an empty set
+// set([1, 2])  => the elements of an array
+// set(1, 2, 3) => the arguments themselves
+func BuiltinFuncSet(host *Host, args ...Object) Object {
+    if len(args) == 1 {
+        if array, ok := args[0].(*Array); ok {
+            args = array.Elements
+        }
+    }
+
+    set := NewSet()
+    for _, arg := range args {
+        if !set.Add(arg) {
+            return unusableAsSetElement(arg)
+        }
+    }
+
+    return set
+}
+
+func BuiltinFuncIsSet(host *Host, args ...Object) Object {
+    return isType(args, SET_OBJ)
+}
+
+func BuiltinFuncAdd(host *Host, args ...Object) Object {
+    set, errObj := setAndElement("add", args)
+    if errObj != nil {
+        return errObj
+    }
+
+    result := NewSet()
+    for _, e := range set.Values() {
+        result.Add(e)
+    }
+    if !result.Add(args[1]) {
+        return unusableAsSetElement(args[1])
+    }
+
+    return result
+}
+
+func BuiltinFuncRemove(host *Host, args ...Object) Object {
+    set, errObj := setAndElement("remove", args)
+    if errObj != nil {
+        return errObj
+    }
+
+    if _, ok := args[1].(Hashable); !ok {
+        return unusableAsSetElement(args[1])
+    }
+
+    removed := NewSet()
+    removed.Add(args[1])
+
+    return setDifference(set, removed)
+}
+
+func BuiltinFuncContains(host *Host, args ...Object) Object {
+    set, errObj := setAndElement("contains", args)
+    if errObj != nil {
+        return errObj
+    }
+
+    return nativeBoolToBooleanObject(set.Contains(args[1]))
+}
+
+func BuiltinFuncUnion(host *Host, args ...Object) Object {
+    left, right, errObj := twoSets("union", args)
+    if errObj != nil {
+        return errObj
+    }
+
+    result := NewSet()
+    for _, e := range left.Values() {
+        result.Add(e)
+    }
+    for _, e := range right.Values() {
+        result.Add(e)
+    }
+
+    return result
+}
+
+func BuiltinFuncIntersection(host *Host, args ...Object) Object {
+    left, right, errObj := twoSets("intersection", args)
+    if errObj != nil {
+        return errObj
+    }
+
+    result := NewSet()
+    for _, e := range left.Values() {
+        if right.Contains(e) {
+            result.Add(e)
+        }
+    }
+
+    return result
+}
+
+func BuiltinFuncDifference(host *Host, args ...Object) Object {
+    left, right, errObj := twoSets("difference", args)
+    if errObj != nil {
+        return errObj
+    }
+
+    return setDifference(left, right)
+}
+
+// to_array(s) lists the elements in insertion order, so a set can be
+// walked with first and rest
+func BuiltinFuncToArray(host *Host, args ...Object) Object {
+    if len(args) != 1 {
+        return newErrorObejct("wrong number of arguments. got=%d, want=1", len(args))
+    }
+
+    set, ok := args[0].(*Set)
+    if !ok {
+        return newErrorObejct("argument to `to_array` must be SET, got %s", args[0].Type())
+    }
+
+    return &Array{Elements: set.Values()}
+}
+
+func setDifference(left, right *Set) *Set {
+    result := NewSet()
+    for _, e := range left.Values() {
+        if !right.Contains(e) {
+            result.Add(e)
+        }
+    }
+
+    return result
+}
+
+func setAndElement(name string, args []Object) (*Set, *Error) {
+    if len(args) != 2 {
+        return nil, newErrorObejct("wrong number of arguments. got=%d, want=2", len(args))
+    }
+
+    set, ok := args[0].(*Set)
+    if !ok {
+        return nil, newErrorObejct("argument to `%s` must be SET, got %s", name, args[0].Type())
+    }
+
+    return set, nil
+}
+
+func twoSets(name string, args []Object) (*Set, *Set, *Error) {
+    if len(args) != 2 {
+        return nil, nil, newErrorObejct("wrong number of arguments. got=%d, want=2", len(args))
+    }
+
+    left, ok1  := args[0].(*Set)
+    right, ok2 := args[1].(*Set)
+    if !ok1 || !ok2 {
+        return nil, nil, newErrorObejct("arguments to `%s` must be SET, got %s and %s",
+            name, args[0].Type(), args[1].Type())
+    }
+
+    return left, right, nil
+}
+
+func unusableAsSetElement(obj Object) *Error {
+    return newErrorObejct("unusable as set element: %s", obj.Type())
+}
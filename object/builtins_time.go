@@ -0,0 +1,133 @@
+package object
+
+import "time"
+
+// Times are integers counting milliseconds since the Unix epoch, and
+// durations are integers counting milliseconds, so that duration
+// arithmetic is plain integer arithmetic: now() + duration("5m").
+
+var timeLayouts = map[string]string{
+    "RFC3339":  time.RFC3339,
+    "RFC1123":  time.RFC1123,
+    "DateTime": time.DateTime,
+    "DateOnly": time.DateOnly,
+    "TimeOnly": time.TimeOnly,
+}
+
+func BuiltinFuncNow(host *Host, args ...Object) Object {
+    if len(args) != 0 {
+        return newErrorObejct("wrong number of arguments. got=%d, want=0", len(args))
+    }
+
+    return NewInteger(host.clock().Now().UnixMilli())
+}
+
+func BuiltinFuncSleep(host *Host, args ...Object) Object {
+    if len(args) != 1 {
+        return newErrorObejct("wrong number of arguments. got=%d, want=1", len(args))
+    }
+
+    ms, ok := args[0].(*Integer)
+    if !ok {
+        return newErrorObejct("argument to `sleep` must be INTEGER, got %s", args[0].Type())
+    }
+
+    host.clock().Sleep(time.Duration(ms.Value) * time.Millisecond)
+    return nil
+}
+
+// format_time(ms)         => RFC 3339 in UTC
+// format_time(ms, layout) => layout is a Go reference layout or one of the
+//                            names in timeLayouts
+func BuiltinFuncFormatTime(host *Host, args ...Object) Object {
+    if len(args) != 1 && len(args) != 2 {
+        return newErrorObejct("wrong number of arguments. got=%d, want=1 or 2", len(args))
+    }
+
+    ms, ok := args[0].(*Integer)
+    if !ok {
+        return newErrorObejct("argument to `format_time` must be INTEGER, got %s", args[0].Type())
+    }
+
+    layout, errObj := layoutArg("format_time", args[1:])
+    if errObj != nil {
+        return errObj
+    }
+
+    t := time.UnixMilli(ms.Value).UTC()
+    return &String{Value: t.Format(layout)}
+}
+
+func BuiltinFuncParseTime(host *Host, args ...Object) Object {
+    if len(args) != 1 && len(args) != 2 {
+        return newErrorObejct("wrong number of arguments. got=%d, want=1 or 2", len(args))
+    }
+
+    str, ok := args[0].(*String)
+    if !ok {
+        return newErrorObejct("argument to `parse_time` must be STRING, got %s", args[0].Type())
+    }
+
+    layout, errObj := layoutArg("parse_time", args[1:])
+    if errObj != nil {
+        return errObj
+    }
+
+    t, err := time.Parse(layout, str.Value)
+    if err != nil {
+        return newErrorObejct("parse_time: %s", err)
+    }
+
+    return NewInteger(t.UnixMilli())
+}
+
+// duration("1h30m") => 5400000
+func BuiltinFuncDuration(host *Host, args ...Object) Object {
+    if len(args) != 1 {
+        return newErrorObejct("wrong number of arguments. got=%d, want=1", len(args))
+    }
+
+    str, ok := args[0].(*String)
+    if !ok {
+        return newErrorObejct("argument to `duration` must be STRING, got %s", args[0].Type())
+    }
+
+    d, err := time.ParseDuration(str.Value)
+    if err != nil {
+        return newErrorObejct("duration: %s", err)
+    }
+
+    return NewInteger(d.Milliseconds())
+}
+
+// format_duration(5400000) => "1h30m0s"
+func BuiltinFuncFormatDuration(host *Host, args ...Object) Object {
+    if len(args) != 1 {
+        return newErrorObejct("wrong number of arguments. got=%d, want=1", len(args))
+    }
+
+    ms, ok := args[0].(*Integer)
+    if !ok {
+        return newErrorObejct("argument to `format_duration` must be INTEGER, got %s", args[0].Type())
+    }
+
+    d := time.Duration(ms.Value) * time.Millisecond
+    return &String{Value: d.String()}
+}
+
+func layoutArg(name string, args []Object) (string, *Error) {
+    if len(args) == 0 {
+        return time.RFC3339, nil
+    }
+
+    layout, ok := args[0].(*String)
+    if !ok {
+        return "", newErrorObejct("layout to `%s` must be STRING, got %s", name, args[0].Type())
+    }
+
+    if named, ok := timeLayouts[layout.Value]; ok {
+        return named, nil
+    }
+
+    return layout.Value, nil
+}
@@ -0,0 +1,120 @@
+package object
+
+import "strconv"
+
+func BuiltinFuncType(host *Host, args ...Object) Object {
+    if len(args) != 1 {
+        return newErrorObejct("wrong number of arguments. got=%d, want=1", len(args))
+    }
+
+    t := args[0].Type()
+    if name, ok := publicTypeNames[t]; ok {
+        t = name
+    }
+
+    return &String{Value: string(t)}
+}
+
+// what type reports for the types that only one engine has, so that scripts
+// see the same names on both
+var publicTypeNames = map[ObjectType]ObjectType{
+    COMPILED_FN_OBJ: FUNCTION_OBJ,
+}
+
+func BuiltinFuncIsInt(host *Host, args ...Object) Object {
+    return isType(args, INTEGER_OBJ)
+}
+
+func BuiltinFuncIsString(host *Host, args ...Object) Object {
+    return isType(args, STRING_OBJ)
+}
+
+func BuiltinFuncIsArray(host *Host, args ...Object) Object {
+    return isType(args, ARRAY_OBJ)
+}
+
+func BuiltinFuncIsHash(host *Host, args ...Object) Object {
+    return isType(args, HASH_OBJ)
+}
+
+func BuiltinFuncIsFn(host *Host, args ...Object) Object {
+    return isType(args, FUNCTION_OBJ, COMPILED_FN_OBJ, BUILTIN_OBJ)
+}
+
+func BuiltinFuncInt(host *Host, args ...Object) Object {
+    if len(args) != 1 {
+        return newErrorObejct("wrong number of arguments. got=%d, want=1", len(args))
+    }
+
+    switch arg := args[0].(type) {
+    case *Integer:
+        return arg
+    case *Boolean:
+        if arg.Value {
+            return NewInteger(1)
+        }
+        return NewInteger(0)
+    case *String:
+        value, err := strconv.ParseInt(arg.Value, 10, 64)
+        if err != nil {
+            return newErrorObejct("could not convert %q to INTEGER", arg.Value)
+        }
+        return NewInteger(value)
+    default:
+        return newErrorObejct("argument to `int` not supported, got %s", arg.Type())
+    }
+}
+
+func BuiltinFuncStr(host *Host, args ...Object) Object {
+    if len(args) != 1 {
+        return newErrorObejct("wrong number of arguments. got=%d, want=1", len(args))
+    }
+
+    switch arg := args[0].(type) {
+    case *String:
+        return arg
+    case *Bytes:
+        return &String{Value: string(arg.Value)}
+    case *Error:
+        return newErrorObejct("argument to `str` not supported, got %s", arg.Type())
+    default:
+        return &String{Value: arg.Inspect()}
+    }
+}
+
+func BuiltinFuncBool(host *Host, args ...Object) Object {
+    if len(args) != 1 {
+        return newErrorObejct("wrong number of arguments. got=%d, want=1", len(args))
+    }
+
+    switch arg := args[0].(type) {
+    case *Boolean:
+        return nativeBoolToBooleanObject(arg.Value)
+    case *Null:
+        return FALSE
+    default:
+        return TRUE
+    }
+}
+
+func isType(args []Object, types ...ObjectType) Object {
+    if len(args) != 1 {
+        return newErrorObejct("wrong number of arguments. got=%d, want=1", len(args))
+    }
+
+    for _, t := range types {
+        if args[0].Type() == t {
+            return TRUE
+        }
+    }
+
+    return FALSE
+}
+
+func nativeBoolToBooleanObject(b bool) *Boolean {
+    if b {
+        return TRUE
+    }
+
+    return FALSE
+}
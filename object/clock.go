@@ -0,0 +1,50 @@
+package object
+
+import (
+    "sync"
+    "time"
+)
+
+// Clock is where the time builtins read the current time from and how
+// they wait. Hosts swap in a ManualClock to make scripts deterministic.
+type Clock interface {
+    Now() time.Time
+    Sleep(d time.Duration)
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time { return time.Now() }
+
+func (systemClock) Sleep(d time.Duration) { time.Sleep(d) }
+
+var SystemClock Clock = systemClock{}
+
+// ManualClock only moves when Sleep or Advance is called, so a script that
+// sleeps returns immediately and observes exactly the time it slept for.
+type ManualClock struct {
+    mu  sync.Mutex
+    now time.Time
+}
+
+func NewManualClock(start time.Time) *ManualClock {
+    return &ManualClock{now: start}
+}
+
+func (c *ManualClock) Now() time.Time {
+    c.mu.Lock()
+    defer c.mu.Unlock()
+
+    return c.now
+}
+
+func (c *ManualClock) Sleep(d time.Duration) {
+    c.Advance(d)
+}
+
+func (c *ManualClock) Advance(d time.Duration) {
+    c.mu.Lock()
+    defer c.mu.Unlock()
+
+    c.now = c.now.Add(d)
+}
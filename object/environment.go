@@ -3,19 +3,35 @@ package object
 type Environment struct {
     store map[string]Object
     outer *Environment
+    host  *Host
 }
 
 func NewEnvironment() *Environment {
+    return NewEnvironmentWithHost(NewHost())
+}
+
+func NewEnvironmentWithHost(host *Host) *Environment {
     s := make(map[string]Object)
-    return &Environment{store: s, outer: nil}
+    return &Environment{store: s, outer: nil, host: host}
 }
 
 func NewEnclosedEnvironment(outer *Environment) *Environment {
-    env := NewEnvironment()
+    env := NewEnvironmentWithHost(outer.host)
     env.outer = outer
     return env
 }
 
+// like NewEnclosedEnvironment, but the builtins called in it see host
+func NewEnclosedEnvironmentWithHost(outer *Environment, host *Host) *Environment {
+    env := NewEnvironmentWithHost(host)
+    env.outer = outer
+    return env
+}
+
+func (e *Environment) Host() *Host {
+    return e.host
+}
+
 func (e *Environment) Get(name string) (Object, bool) {
     obj, ok := e.store[name]
     if !ok && e.outer != nil {
@@ -27,4 +43,4 @@ func (e *Environment) Get(name string) (Object, bool) {
 func (e *Environment) Set(name string, val Object) Object {
     e.store[name] = val
     return val
-}
\ No newline at end of file
+}
@@ -0,0 +1,115 @@
+package object
+
+import (
+    "errors"
+    "fmt"
+    "io"
+    "io/fs"
+    "os"
+    "path/filepath"
+    "sort"
+)
+
+var ErrReadOnly = errors.New("file system is read-only")
+
+// FileSystem is the capability every file builtin goes through. A Host
+// without one denies all file access.
+type FileSystem interface {
+    ReadFile(name string) ([]byte, error)
+    WriteFile(name string, data []byte) error
+    ReadDir(name string) ([]string, error)
+    Exists(name string) (bool, error)
+}
+
+// DirFileSystem grants access to the tree below a single directory. Names
+// are relative to that directory and may not escape it, not even through
+// symlinks.
+type DirFileSystem struct {
+    root     *os.Root
+    readOnly bool
+}
+
+func NewDirFileSystem(dir string, readOnly bool) (*DirFileSystem, error) {
+    root, err := os.OpenRoot(dir)
+    if err != nil {
+        return nil, err
+    }
+
+    return &DirFileSystem{root: root, readOnly: readOnly}, nil
+}
+
+func (d *DirFileSystem) Close() error {
+    return d.root.Close()
+}
+
+// the path of the directory name below the root, for programs that need a
+// working directory rather than file access
+func (d *DirFileSystem) dirPath(name string) (string, error) {
+    info, err := d.root.Stat(name)
+    if err != nil {
+        return "", err
+    }
+    if !info.IsDir() {
+        return "", fmt.Errorf("%s is not a directory", name)
+    }
+
+    return filepath.Join(d.root.Name(), name), nil
+}
+
+func (d *DirFileSystem) ReadFile(name string) ([]byte, error) {
+    f, err := d.root.Open(name)
+    if err != nil {
+        return nil, err
+    }
+    defer f.Close()
+
+    return io.ReadAll(f)
+}
+
+func (d *DirFileSystem) WriteFile(name string, data []byte) error {
+    if d.readOnly {
+        return ErrReadOnly
+    }
+
+    f, err := d.root.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+    if err != nil {
+        return err
+    }
+
+    _, err = f.Write(data)
+    if closeErr := f.Close(); err == nil {
+        err = closeErr
+    }
+
+    return err
+}
+
+func (d *DirFileSystem) ReadDir(name string) ([]string, error) {
+    f, err := d.root.Open(name)
+    if err != nil {
+        return nil, err
+    }
+    defer f.Close()
+
+    entries, err := f.ReadDir(-1)
+    if err != nil {
+        return nil, err
+    }
+
+    names := make([]string, len(entries))
+    for i, e := range entries {
+        names[i] = e.Name()
+    }
+    sort.Strings(names)
+
+    return names, nil
+}
+
+func (d *DirFileSystem) Exists(name string) (bool, error) {
+    _, err := d.root.Stat(name)
+    if errors.Is(err, fs.ErrNotExist) {
+        return false, nil
+    }
+
+    return err == nil, err
+}
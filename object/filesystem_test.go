@@ -0,0 +1,85 @@
+package object
+
+import (
+    "errors"
+    "os"
+    "path/filepath"
+    "testing"
+)
+
+func TestDirFileSystem(t *testing.T) {
+    dir := t.TempDir()
+
+    fs, err := NewDirFileSystem(dir, false)
+    if err != nil {
+        t.Fatalf("NewDirFileSystem failed: %s", err)
+    }
+    defer fs.Close()
+
+    err = fs.WriteFile("report.txt", []byte("ok"))
+    if err != nil {
+        t.Fatalf("WriteFile failed: %s", err)
+    }
+
+    data, err := fs.ReadFile("report.txt")
+    if err != nil || string(data) != "ok" {
+        t.Errorf("ReadFile returned %q, %v", data, err)
+    }
+
+    found, err := fs.Exists("report.txt")
+    if err != nil || !found {
+        t.Errorf("Exists(report.txt) returned %t, %v", found, err)
+    }
+
+    found, err = fs.Exists("missing.txt")
+    if err != nil || found {
+        t.Errorf("Exists(missing.txt) returned %t, %v", found, err)
+    }
+
+    names, err := fs.ReadDir(".")
+    if err != nil || len(names) != 1 || names[0] != "report.txt" {
+        t.Errorf("ReadDir returned %v, %v", names, err)
+    }
+}
+
+func TestDirFileSystemConfinement(t *testing.T) {
+    outside := t.TempDir()
+    secret  := filepath.Join(outside, "secret")
+    if err := os.WriteFile(secret, []byte("x"), 0644); err != nil {
+        t.Fatal(err)
+    }
+
+    dir := t.TempDir()
+    if err := os.Symlink(secret, filepath.Join(dir, "link")); err != nil {
+        t.Skipf("symlinks unavailable: %s", err)
+    }
+
+    fs, err := NewDirFileSystem(dir, false)
+    if err != nil {
+        t.Fatalf("NewDirFileSystem failed: %s", err)
+    }
+    defer fs.Close()
+
+    for _, name := range []string{"../secret", secret, "link"} {
+        if _, err := fs.ReadFile(name); err == nil {
+            t.Errorf("ReadFile(%q) escaped the root", name)
+        }
+    }
+
+    if err := fs.WriteFile("../escape", []byte("x")); err == nil {
+        t.Errorf("WriteFile(../escape) escaped the root")
+    }
+}
+
+func TestDirFileSystemReadOnly(t *testing.T) {
+    fs, err := NewDirFileSystem(t.TempDir(), true)
+    if err != nil {
+        t.Fatalf("NewDirFileSystem failed: %s", err)
+    }
+    defer fs.Close()
+
+    err = fs.WriteFile("report.txt", []byte("ok"))
+    if !errors.Is(err, ErrReadOnly) {
+        t.Errorf("expected ErrReadOnly, got %v", err)
+    }
+}
@@ -0,0 +1,151 @@
+package object
+
+import (
+    "bufio"
+    "io"
+    "math/rand"
+    "net"
+    "net/http"
+    "os"
+    "reflect"
+    "strings"
+    "time"
+)
+
+// Host carries the state and capabilities an embedding program grants to
+// a running script. Both engines hand it to every builtin they call.
+type Host struct {
+    Rand   *rand.Rand
+
+    // nil denies the file builtins any access
+    FS     FileSystem
+
+    Clock  Clock
+
+    Stdout io.Writer
+    Stderr io.Writer
+    Stdin  io.Reader
+
+    // what the args and env builtins see, empty unless the embedding
+    // program fills them in
+    Args   []string
+    Env    map[string]string
+
+    // the programs exec may run, nil disables exec altogether
+    AllowedCommands []string
+
+    // turns the http builtins off
+    NetworkDisabled bool
+    // what the http builtins send requests with, http.DefaultClient if nil
+    HTTPClient      *http.Client
+    // what serve listens with, net.Listen if nil
+    Listen          func(network, addr string) (net.Listener, error)
+
+    // buffers Stdin for read_line, so no input is lost between calls
+    stdin       *bufio.Reader
+    stdinSource io.Reader
+}
+
+func NewHost() *Host {
+    return &Host{
+        Rand:   rand.New(rand.NewSource(time.Now().UnixNano())),
+        Clock:  SystemClock,
+        Stdout: os.Stdout,
+        Stderr: os.Stderr,
+        Stdin:  os.Stdin,
+    }
+}
+
+// Environ returns the environment of the current process, for use as
+// Host.Env
+func Environ() map[string]string {
+    env := make(map[string]string)
+    for _, kv := range os.Environ() {
+        if k, v, ok := strings.Cut(kv, "="); ok {
+            env[k] = v
+        }
+    }
+
+    return env
+}
+
+// Seed resets the random generator so that `random` yields a reproducible
+// sequence
+func (h *Host) Seed(seed int64) {
+    h.Rand = rand.New(rand.NewSource(seed))
+}
+
+// the generator behind `random`, a Host built without one gets a time
+// seeded generator on first use
+func (h *Host) random() *rand.Rand {
+    if h.Rand == nil {
+        h.Seed(time.Now().UnixNano())
+    }
+
+    return h.Rand
+}
+
+// what the time builtins use, the system clock if Clock is nil
+func (h *Host) clock() Clock {
+    if h.Clock == nil {
+        return SystemClock
+    }
+
+    return h.Clock
+}
+
+// where puts and print write, a nil Stdout discards the output
+func (h *Host) stdout() io.Writer {
+    if h.Stdout == nil {
+        return io.Discard
+    }
+
+    return h.Stdout
+}
+
+// where eputs writes, a nil Stderr discards the output
+func (h *Host) stderr() io.Writer {
+    if h.Stderr == nil {
+        return io.Discard
+    }
+
+    return h.Stderr
+}
+
+// ReadLine returns the next line of Stdin without its line ending. It
+// reports io.EOF once the input is exhausted.
+func (h *Host) ReadLine() (string, error) {
+    if h.Stdin == nil {
+        return "", io.EOF
+    }
+
+    if !sameReader(h.stdinSource, h.Stdin) {
+        if r, ok := h.Stdin.(*bufio.Reader); ok {
+            h.stdin = r
+        } else {
+            h.stdin = bufio.NewReader(h.Stdin)
+        }
+        h.stdinSource = h.Stdin
+    }
+
+    line, err := h.stdin.ReadString('\n')
+    if err != nil && (err != io.EOF || line == "") {
+        return "", err
+    }
+
+    return strings.TrimRight(line, "\r\n"), nil
+}
+
+// reports whether Stdin is still the reader ReadLine buffered. Comparing
+// the interfaces directly panics for readers of an uncomparable type, those
+// are taken to be unchanged as long as the type is.
+func sameReader(a, b io.Reader) bool {
+    if reflect.TypeOf(a) != reflect.TypeOf(b) {
+        return false
+    }
+    if a != nil && !reflect.TypeOf(a).Comparable() {
+        return true
+    }
+
+    return a == b
+}
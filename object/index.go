@@ -0,0 +1,114 @@
+package object
+
+import "fmt"
+
+// Both engines index and slice through these, so that they agree on the
+// edge cases.
+
+// IndexSequence returns the element of an array, the one byte string of a
+// string or the byte of bytes at idx. Negative indices count from the end,
+// out of range ones give NULL.
+func IndexSequence(left Object, idx int64) Object {
+    var length int64
+
+    switch left := left.(type) {
+    case *Array:
+        length = int64(len(left.Elements))
+    case *String:
+        length = int64(len(left.Value))
+    case *Bytes:
+        length = int64(len(left.Value))
+    default:
+        return NULL
+    }
+
+    if idx < 0 {
+        idx += length
+    }
+
+    if idx < 0 || idx >= length {
+        return NULL
+    }
+
+    switch left := left.(type) {
+    case *Array:
+        return left.Elements[idx]
+    case *Bytes:
+        return NewInteger(int64(left.Value[idx]))
+    default:
+        str := left.(*String).Value
+        return &String{Value: str[idx : idx+1]}
+    }
+}
+
+// Slice returns a copy of the part of an array, string or bytes between
+// low and high, either of which may be NULL for the start or the end
+func Slice(left, low, high Object) (Object, error) {
+    var length int64
+
+    switch left := left.(type) {
+    case *Array:
+        length = int64(len(left.Elements))
+    case *String:
+        length = int64(len(left.Value))
+    case *Bytes:
+        length = int64(len(left.Value))
+    default:
+        return nil, fmt.Errorf("slice operator not supported: %s", left.Type())
+    }
+
+    start, ok := sliceBound(low, 0, length)
+    if !ok {
+        return nil, fmt.Errorf("slice bounds must be INTEGER, got %s", low.Type())
+    }
+
+    end, ok := sliceBound(high, length, length)
+    if !ok {
+        return nil, fmt.Errorf("slice bounds must be INTEGER, got %s", high.Type())
+    }
+
+    if start > end {
+        start = end
+    }
+
+    switch left := left.(type) {
+    case *Array:
+        elements := make([]Object, end - start)
+        copy(elements, left.Elements[start:end])
+        return &Array{Elements: elements}, nil
+    case *Bytes:
+        value := make([]byte, end - start)
+        copy(value, left.Value[start:end])
+        return &Bytes{Value: value}, nil
+    default:
+        return &String{Value: left.(*String).Value[start:end]}, nil
+    }
+}
+
+// resolves a slice bound against the length of the sliced value: null
+// means the default, negative values count from the end, and the result is
+// clamped to [0, length]
+func sliceBound(bound Object, def, length int64) (int64, bool) {
+    if bound == NULL {
+        return def, true
+    }
+
+    integer, ok := bound.(*Integer)
+    if !ok {
+        return 0, false
+    }
+
+    idx := integer.Value
+    if idx < 0 {
+        idx += length
+    }
+
+    if idx < 0 {
+        return 0, true
+    }
+    if idx > length {
+        return length, true
+    }
+
+    return idx, true
+}
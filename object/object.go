@@ -3,6 +3,8 @@ package object
 import (
     "fmt"
     "bytes"
+    "encoding/hex"
+    "regexp"
     "strings"
     "hash/fnv"
     "myMonkey/ast"
@@ -23,6 +25,12 @@ const (
     FUNCTION_OBJ     = "FUNCTION"
     BUILTIN_OBJ      = "BUILTIN"
     COMPILED_FN_OBJ  = "COMPILED_FN_OBJ"
+    REGEX_OBJ        = "REGEX"
+    SET_OBJ          = "SET"
+    BYTES_OBJ        = "BYTES"
+    EXIT_OBJ         = "EXIT"
+    SERVE_OBJ        = "SERVE"
+    FIELD_KEY_OBJ    = "FIELD_KEY"
 )
 
 type Object interface {
@@ -30,6 +38,14 @@ type Object interface {
     Inspect() string
 }
 
+// the engines and the builtins share these, so that identity comparisons
+// such as `bool(1) == true` hold no matter who produced the value
+var (
+    NULL  = &Null{}
+    TRUE  = &Boolean{Value: true}
+    FALSE = &Boolean{Value: false}
+)
+
 type Null struct { }
 
 func (n *Null) Type() ObjectType { return NULL_OBJ }
@@ -44,10 +60,45 @@ func (e *Error) Type() ObjectType { return ERROR_OBJ }
 
 func (e *Error) Inspect() string { return "ERROR:" + e.Message }
 
+// returned by the exit builtin, the engines stop running the script as
+// soon as they see it
+type Exit struct {
+    Code int
+}
+
+func (e *Exit) Type() ObjectType { return EXIT_OBJ }
+
+func (e *Exit) Inspect() string { return fmt.Sprintf("exit(%d)", e.Code) }
+
 type Integer struct {
     Value int64
 }
 
+// integers in this range are preallocated and shared, it covers most
+// counters, indexes and lengths a program goes through
+const (
+    smallIntegerMin = -256
+    smallIntegerMax = 1024
+)
+
+var smallIntegers = func() []*Integer {
+    integers := make([]*Integer, smallIntegerMax - smallIntegerMin + 1)
+    for i := range integers {
+        integers[i] = &Integer{Value: int64(i + smallIntegerMin)}
+    }
+    return integers
+}()
+
+// NewInteger returns the shared Integer for small values and allocates the
+// others. Integers are never modified, so sharing them is safe.
+func NewInteger(value int64) *Integer {
+    if smallIntegerMin <= value && value <= smallIntegerMax {
+        return smallIntegers[value - smallIntegerMin]
+    }
+
+    return &Integer{Value: value}
+}
+
 func (i *Integer) Type() ObjectType { return INTEGER_OBJ }
 
 func (i *Integer) Inspect() string { return fmt.Sprintf("%d", i.Value) }
@@ -70,6 +121,36 @@ func (s *String) HashKey() HashKey {
     return HashKey{Type: s.Type(), Value: h.Sum64()}
 }
 
+// the key the compiler indexes with for obj.field. It finds the same entry
+// as the STRING Name, but a missing field is an error rather than null.
+type FieldKey struct {
+    Name string
+}
+
+func (f *FieldKey) Type() ObjectType { return FIELD_KEY_OBJ }
+
+func (f *FieldKey) Inspect() string { return f.Name }
+
+func (f *FieldKey) HashKey() HashKey {
+    return (&String{Value: f.Name}).HashKey()
+}
+
+// raw binary data, as opposed to String which is meant to hold text
+type Bytes struct {
+    Value []byte
+}
+
+func (b *Bytes) Type() ObjectType { return BYTES_OBJ }
+
+func (b *Bytes) Inspect() string { return "bytes(" + hex.EncodeToString(b.Value) + ")" }
+
+func (b *Bytes) HashKey() HashKey {
+    h := fnv.New64a()
+    h.Write(b.Value)
+
+    return HashKey{Type: b.Type(), Value: h.Sum64()}
+}
+
 type Boolean struct {
     Value bool
 }
@@ -132,6 +213,83 @@ func (h *Hash) Inspect() string {
     return out.String()
 }
 
+// Set holds Hashable elements and remembers the order they were added in
+type Set struct {
+    Elements map[HashKey]Object
+    keys     []HashKey
+}
+
+func NewSet() *Set {
+    return &Set{Elements: make(map[HashKey]Object)}
+}
+
+func (s *Set) Type() ObjectType { return SET_OBJ }
+
+func (s *Set) Inspect() string {
+    var out bytes.Buffer
+
+    elements := []string{}
+    for _, e := range s.Values() {
+        elements = append(elements, e.Inspect())
+    }
+
+    out.WriteString("set(")
+    out.WriteString(strings.Join(elements, ", "))
+    out.WriteString(")")
+
+    return out.String()
+}
+
+// Add reports false if obj can't be a set element
+func (s *Set) Add(obj Object) bool {
+    hashable, ok := obj.(Hashable)
+    if !ok {
+        return false
+    }
+
+    key := hashable.HashKey()
+    if _, ok := s.Elements[key]; !ok {
+        s.Elements[key] = obj
+        s.keys = append(s.keys, key)
+    }
+
+    return true
+}
+
+func (s *Set) Contains(obj Object) bool {
+    hashable, ok := obj.(Hashable)
+    if !ok {
+        return false
+    }
+
+    _, ok = s.Elements[hashable.HashKey()]
+    return ok
+}
+
+// Equal reports whether both sets hold the same elements, in any order
+func (s *Set) Equal(other *Set) bool {
+    if len(s.Elements) != len(other.Elements) {
+        return false
+    }
+
+    for key := range s.Elements {
+        if _, ok := other.Elements[key]; !ok {
+            return false
+        }
+    }
+
+    return true
+}
+
+func (s *Set) Values() []Object {
+    values := make([]Object, len(s.keys))
+    for i, key := range s.keys {
+        values[i] = s.Elements[key]
+    }
+
+    return values
+}
+
 type ReturnValue struct {
     Value Object
 }
@@ -164,7 +322,7 @@ func (f *Function) Inspect() string {
     return out.String()
 }
 
-type BuiltinFunction func(args ...Object) Object
+type BuiltinFunction func(host *Host, args ...Object) Object
 type Builtin struct {
     Fn BuiltinFunction
 }
@@ -196,4 +354,12 @@ type CompiledFunction struct {
 func (cf *CompiledFunction) Type() ObjectType { return COMPILED_FN_OBJ }
 func (cf *CompiledFunction) Inspect() string {
     return fmt.Sprintf("CompiledFunction[%p]", cf)
-}
\ No newline at end of file
+}
+
+type Regex struct {
+    Regexp *regexp.Regexp
+}
+
+func (r *Regex) Type() ObjectType { return REGEX_OBJ }
+
+func (r *Regex) Inspect() string { return "regex(" + r.Regexp.String() + ")" }
@@ -1,6 +1,13 @@
 package object
 
-import "testing"
+import (
+    "fmt"
+    "io"
+    "math"
+    "strings"
+    "testing"
+    "time"
+)
 
 func TestStringHashKey(t *testing.T) {
     hello1 := &String{Value: "Hello World"}
@@ -57,4 +64,184 @@ func TestIntegerHashKey(t *testing.T) {
     if one1.HashKey() == two1.HashKey() {
         t.Errorf("integers with twoerent content have same hash keys")
     }
-}
\ No newline at end of file
+}
+
+func TestNewInteger(t *testing.T) {
+    for _, value := range []int64{smallIntegerMin, -1, 0, 1, smallIntegerMax} {
+        integer := NewInteger(value)
+        if integer.Value != value {
+            t.Errorf("wrong value. want=%d, got=%d", value, integer.Value)
+        }
+        if NewInteger(value) != integer {
+            t.Errorf("small integer %d is not shared", value)
+        }
+    }
+
+    for _, value := range []int64{smallIntegerMin - 1, smallIntegerMax + 1} {
+        integer := NewInteger(value)
+        if integer.Value != value {
+            t.Errorf("wrong value. want=%d, got=%d", value, integer.Value)
+        }
+        if NewInteger(value) == integer {
+            t.Errorf("integer %d outside the cache is shared", value)
+        }
+    }
+}
+
+func TestRandomRange(t *testing.T) {
+    // a Host without a generator still works
+    host := &Host{}
+
+    ranges := [][2]int64{
+        {0, 10},
+        {-5, 5},
+        {math.MinInt64, math.MaxInt64},
+        {-1, math.MaxInt64},
+        {math.MinInt64, 1},
+    }
+
+    for _, r := range ranges {
+        for i := 0; i < 100; i++ {
+            result := BuiltinFuncRandom(host, NewInteger(r[0]), NewInteger(r[1]))
+            integer, ok := result.(*Integer)
+            if !ok {
+                t.Fatalf("random(%d, %d) is not Integer. got=%T (%+v)", r[0], r[1], result, result)
+            }
+            if integer.Value < r[0] || integer.Value >= r[1] {
+                t.Fatalf("random(%d, %d) out of range. got=%d", r[0], r[1], integer.Value)
+            }
+        }
+    }
+
+    if _, ok := BuiltinFuncRandom(host).(*Integer); !ok {
+        t.Errorf("random() is not Integer")
+    }
+}
+
+// a reader of a type that == panics on
+type uncomparableReader struct {
+    *strings.Reader
+    _ []int
+}
+
+func TestHostWithoutStreams(t *testing.T) {
+    host := &Host{}
+
+    BuiltinFuncPuts(host, &String{Value: "a"})
+    BuiltinFuncEputs(host, &String{Value: "b"})
+    BuiltinFuncPrint(host, &String{Value: "c"})
+
+    before := time.Now().UnixMilli()
+    now, ok := BuiltinFuncNow(host).(*Integer)
+    if !ok || now.Value < before {
+        t.Errorf("now without a Clock should read the system clock. got=%+v", now)
+    }
+    BuiltinFuncSleep(host, NewInteger(1))
+
+    if _, err := host.ReadLine(); err != io.EOF {
+        t.Errorf("ReadLine without Stdin should report io.EOF. got=%v", err)
+    }
+
+    host.Stdin = uncomparableReader{Reader: strings.NewReader("one\ntwo\n")}
+    for _, want := range []string{"one", "two"} {
+        line, err := host.ReadLine()
+        if err != nil || line != want {
+            t.Errorf("wrong line. want=%q, got=%q (%v)", want, line, err)
+        }
+    }
+
+    host.Stdin = strings.NewReader("three\n")
+    if line, _ := host.ReadLine(); line != "three" {
+        t.Errorf("replaced Stdin not read. got=%q", line)
+    }
+}
+
+func TestIndexAndSlice(t *testing.T) {
+    str := &String{Value: "hello"}
+
+    tests := []struct {
+        result   Object
+        expected string
+    }{
+        {IndexSequence(str, 1), "e"},
+        {IndexSequence(str, -1), "o"},
+        {IndexSequence(str, 5), "null"},
+        {IndexSequence(&Bytes{Value: []byte{7, 8}}, -2), "7"},
+        {IndexSequence(&Array{Elements: []Object{NewInteger(3)}}, 0), "3"},
+    }
+
+    for _, tt := range tests {
+        if tt.result.Inspect() != tt.expected {
+            t.Errorf("wrong element. want=%s, got=%s", tt.expected, tt.result.Inspect())
+        }
+    }
+
+    result, err := Slice(str, NewInteger(-4), NULL)
+    if err != nil || result.Inspect() != "ello" {
+        t.Errorf("wrong slice. want=ello, got=%v (%v)", result, err)
+    }
+    result, err = Slice(str, NewInteger(4), NewInteger(2))
+    if err != nil || result.Inspect() != "" {
+        t.Errorf("crossed bounds should give an empty slice. got=%v (%v)", result, err)
+    }
+    if _, err := Slice(str, TRUE, NULL); err == nil ||
+        err.Error() != "slice bounds must be INTEGER, got BOOLEAN" {
+        t.Errorf("wrong error for a BOOLEAN bound. got=%v", err)
+    }
+}
+
+func TestRegexCache(t *testing.T) {
+    re1, err := compileRegex("a+b")
+    if err != nil {
+        t.Fatalf("compileRegex failed: %s", err)
+    }
+
+    re2, _ := compileRegex("a+b")
+    if re1 != re2 {
+        t.Errorf("same pattern compiled twice")
+    }
+
+    _, err = compileRegex("a(")
+    if err == nil {
+        t.Errorf("invalid pattern compiled")
+    }
+
+    for i := 0; i < regexCacheSize; i++ {
+        compileRegex(fmt.Sprintf("x%d", i))
+        compileRegex("a+b")
+    }
+
+    if n := len(regexCache.patterns); n > regexCacheSize {
+        t.Errorf("cache grew past its size. want=%d, got=%d", regexCacheSize, n)
+    }
+    if re3, _ := compileRegex("a+b"); re3 != re1 {
+        t.Errorf("pattern in use evicted")
+    }
+    if _, ok := regexCache.patterns["x0"]; ok {
+        t.Errorf("least recently used pattern not evicted")
+    }
+}
+
+func TestSet(t *testing.T) {
+    set := NewSet()
+    set.Add(&Integer{Value: 2})
+    set.Add(&String{Value: "a"})
+    set.Add(&Integer{Value: 2})
+
+    if !set.Add(&Boolean{Value: true}) {
+        t.Errorf("boolean rejected as set element")
+    }
+    if set.Add(&Array{}) {
+        t.Errorf("array accepted as set element")
+    }
+
+    if len(set.Elements) != 3 {
+        t.Fatalf("set has wrong number of elements. got=%d", len(set.Elements))
+    }
+    if !set.Contains(&String{Value: "a"}) || set.Contains(&Integer{Value: 3}) {
+        t.Errorf("Contains gave wrong answer")
+    }
+    if set.Inspect() != "set(2, a, true)" {
+        t.Errorf("set not in insertion order. got=%s", set.Inspect())
+    }
+}
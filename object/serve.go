@@ -0,0 +1,248 @@
+package object
+
+import (
+    "errors"
+    "fmt"
+    "io"
+    "net"
+    "net/http"
+    "sync"
+)
+
+// returned by the serve builtin. A builtin cannot call back into Monkey,
+// so the engine that sees it runs the server itself through Run.
+type Serve struct {
+    Addr    string
+    Handler Object
+}
+
+func (s *Serve) Type() ObjectType { return SERVE_OBJ }
+
+func (s *Serve) Inspect() string { return "serve(" + s.Addr + ")" }
+
+// RequestHandler calls the Monkey handler with a request hash. Engines run
+// every call on an instance of their own, as requests come in concurrently,
+// and hand host, which belongs to that request alone, to the builtins.
+type RequestHandler func(host *Host, request Object) Object
+
+// serve(":8080", fn(req) { {"status": 200, "body": "hi"} })
+func BuiltinFuncServe(host *Host, args ...Object) Object {
+    if len(args) != 2 {
+        return newErrorObejct("wrong number of arguments. got=%d, want=2", len(args))
+    }
+
+    addr, ok := args[0].(*String)
+    if !ok {
+        return newErrorObejct("first argument to `serve` must be STRING, got %s", args[0].Type())
+    }
+
+    switch args[1].Type() {
+    case FUNCTION_OBJ, COMPILED_FN_OBJ, BUILTIN_OBJ:
+    default:
+        return newErrorObejct("second argument to `serve` must be a function, got %s", args[1].Type())
+    }
+
+    if host.NetworkDisabled {
+        return newErrorObejct("serve: network access disabled by host")
+    }
+
+    return &Serve{Addr: addr.Value, Handler: args[1]}
+}
+
+// Run serves HTTP on s.Addr until the listener is closed, turning each
+// request into a hash for handle and its result into the response. A
+// handler that calls exit gets a 503, and stops the server: Run then
+// returns the Exit, so that the script exits as well.
+func (s *Serve) Run(host *Host, handle RequestHandler) Object {
+    listen := host.Listen
+    if listen == nil {
+        listen = net.Listen
+    }
+
+    ln, err := listen("tcp", s.Addr)
+    if err != nil {
+        return newErrorObejct("serve: %s", err)
+    }
+
+    requests := newRequestHosts(host)
+
+    var exit *Exit
+    var once sync.Once
+
+    err = http.Serve(ln, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        request, err := requestHash(r)
+        if err != nil {
+            http.Error(w, err.Error(), http.StatusBadRequest)
+            return
+        }
+
+        response := handle(requests.next(), request)
+        if e, ok := response.(*Exit); ok {
+            http.Error(w, http.StatusText(http.StatusServiceUnavailable),
+                http.StatusServiceUnavailable)
+            once.Do(func() {
+                exit = e
+                ln.Close()
+            })
+            return
+        }
+
+        err = writeResponse(w, response)
+        if err != nil {
+            fmt.Fprintf(requests.stderr, "serve: %s %s: %s\n", r.Method, r.URL.Path, err)
+            http.Error(w, http.StatusText(http.StatusInternalServerError),
+                http.StatusInternalServerError)
+        }
+    }))
+    if errors.Is(err, net.ErrClosed) {
+        if exit != nil {
+            return exit
+        }
+        return nil
+    }
+
+    return newErrorObejct("serve: %s", err)
+}
+
+// hands every request a Host of its own, as handlers run concurrently:
+// each gets its own random generator, seeded from the one of host, and no
+// Stdin, while the writes to Stdout and Stderr are serialised
+type requestHosts struct {
+    mu     sync.Mutex // guards the random generator of host
+    out    sync.Mutex // serialises the writes to stdout and stderr
+    host   *Host
+    stdout io.Writer
+    stderr io.Writer
+}
+
+func newRequestHosts(host *Host) *requestHosts {
+    h := &requestHosts{host: host}
+    h.stdout = &lockedWriter{mu: &h.out, w: host.Stdout}
+    h.stderr = &lockedWriter{mu: &h.out, w: host.Stderr}
+    return h
+}
+
+func (h *requestHosts) next() *Host {
+    h.mu.Lock()
+    defer h.mu.Unlock()
+
+    host       := *h.host
+    host.Stdout = h.stdout
+    host.Stderr = h.stderr
+    host.Stdin  = nil
+    host.stdin, host.stdinSource = nil, nil
+
+    if h.host.Rand != nil {
+        host.Seed(h.host.Rand.Int63())
+    }
+
+    return &host
+}
+
+type lockedWriter struct {
+    mu *sync.Mutex
+    w  io.Writer
+}
+
+func (l *lockedWriter) Write(p []byte) (int, error) {
+    l.mu.Lock()
+    defer l.mu.Unlock()
+
+    if l.w == nil {
+        return len(p), nil
+    }
+    return l.w.Write(p)
+}
+
+// {"method": ..., "path": ..., "query": {...}, "headers": {...}, "body": ...},
+// repeated query parameters and headers keep their first value
+func requestHash(r *http.Request) (Object, error) {
+    body, err := io.ReadAll(r.Body)
+    if err != nil {
+        return nil, err
+    }
+
+    query := make(map[string]Object)
+    for k, v := range r.URL.Query() {
+        query[k] = &String{Value: v[0]}
+    }
+
+    headers := make(map[string]Object)
+    for k, v := range r.Header {
+        headers[k] = &String{Value: v[0]}
+    }
+
+    return stringHash(map[string]Object{
+        "method":  &String{Value: r.Method},
+        "path":    &String{Value: r.URL.Path},
+        "query":   stringHash(query),
+        "headers": stringHash(headers),
+        "body":    &String{Value: string(body)},
+    }), nil
+}
+
+// a STRING is sent as the body of a 200, a HASH may set "status",
+// "headers" and "body"
+func writeResponse(w http.ResponseWriter, response Object) error {
+    switch response := response.(type) {
+    case *String:
+        _, err := io.WriteString(w, response.Value)
+        return err
+    case *Hash:
+        opts, errObj := options("serve", response, "status", "headers", "body")
+        if errObj != nil {
+            return errors.New(errObj.Message)
+        }
+
+        status := http.StatusOK
+        if s, ok := opts["status"]; ok {
+            integer, ok := s.(*Integer)
+            if !ok {
+                return fmt.Errorf("status must be INTEGER, got %s", s.Type())
+            }
+            // WriteHeader panics on anything outside 100..999
+            if integer.Value < 100 || integer.Value > 999 {
+                return fmt.Errorf("status must be in 100..999, got %d", integer.Value)
+            }
+            status = int(integer.Value)
+        }
+
+        if h, ok := opts["headers"]; ok {
+            headers, ok := h.(*Hash)
+            if !ok {
+                return fmt.Errorf("headers must be HASH, got %s", h.Type())
+            }
+
+            for _, pair := range headers.Pairs {
+                k, ok1 := pair.Key.(*String)
+                v, ok2 := pair.Value.(*String)
+                if !ok1 || !ok2 {
+                    return fmt.Errorf("headers must map STRING to STRING, got %s to %s",
+                        pair.Key.Type(), pair.Value.Type())
+                }
+                w.Header().Set(k.Value, v.Value)
+            }
+        }
+
+        var body []byte
+        switch b := opts["body"].(type) {
+        case nil:
+        case *String:
+            body = []byte(b.Value)
+        case *Bytes:
+            body = b.Value
+        default:
+            return fmt.Errorf("body must be STRING or BYTES, got %s", b.Type())
+        }
+
+        w.WriteHeader(status)
+        _, err := w.Write(body)
+        return err
+    case *Error:
+        return errors.New(response.Message)
+    case nil:
+        return errors.New("handler returned nothing")
+    default:
+        return fmt.Errorf("handler returned %s, want HASH or STRING", response.Type())
+    }
+}
@@ -0,0 +1,214 @@
+package object_test
+
+import (
+    "bytes"
+    "io"
+    "net"
+    "net/http"
+    "strings"
+    "sync"
+    "testing"
+
+    "myMonkey/compiler"
+    "myMonkey/evaluator"
+    "myMonkey/lexer"
+    "myMonkey/object"
+    "myMonkey/parser"
+    "myMonkey/vm"
+)
+
+// serve hands control back to the engine that called it, so its tests
+// run the script on both of them
+var engines = []struct {
+    name string
+    run  func(t *testing.T, input string, host *object.Host) object.Object
+}{
+    {"evaluator", func(t *testing.T, input string, host *object.Host) object.Object {
+        p := parser.New(lexer.New(input))
+        return evaluator.Eval(p.ParseProgram(), object.NewEnvironmentWithHost(host))
+    }},
+    {"vm", func(t *testing.T, input string, host *object.Host) object.Object {
+        comp := compiler.New()
+        err := comp.Compile(parser.New(lexer.New(input)).ParseProgram())
+        if err != nil {
+            t.Errorf("compiler error: %s", err)
+            return nil
+        }
+
+        machine := vm.NewWithHost(comp.Bytecode(), host)
+        err = machine.Run()
+        if exit, ok := err.(*vm.ExitError); ok {
+            return &object.Exit{Code: exit.Code}
+        }
+        if err != nil {
+            t.Errorf("vm error: %s", err)
+            return nil
+        }
+
+        return machine.LastPoppedStackElem()
+    }},
+}
+
+const serveInput = `
+let greeting = "hello";
+let routes = {
+    "/missing": fn(req) { {"status": 404, "body": "nope"} },
+    "/bad":     fn(req) { 1 },
+    "/status":  fn(req) { {"status": 1000} },
+};
+
+serve(":0", fn(req) {
+    let route = routes[req["path"]];
+    if (route) {
+        return route(req);
+    }
+
+    {
+        "status":  201,
+        "headers": {"X-Greeting": greeting},
+        "body":    greeting + " " + req["query"]["name"] + req["body"],
+    }
+})
+`
+
+// starts input on the engine in the background, with a host that listens
+// on ln. The result of the script arrives on the channel.
+func startServe(t *testing.T, run func(*testing.T, string, *object.Host) object.Object,
+    input string, stderr io.Writer) (net.Listener, chan object.Object) {
+    t.Helper()
+
+    ln, err := net.Listen("tcp", "127.0.0.1:0")
+    if err != nil {
+        t.Fatal(err)
+    }
+
+    host := object.NewHost()
+    host.Stderr = stderr
+    host.Listen = func(network, addr string) (net.Listener, error) {
+        return ln, nil
+    }
+
+    done := make(chan object.Object)
+    go func() { done <- run(t, input, host) }()
+
+    return ln, done
+}
+
+func TestServe(t *testing.T) {
+    for _, engine := range engines {
+        var stderr bytes.Buffer
+        ln, done := startServe(t, engine.run, serveInput, &stderr)
+
+        tests := []struct {
+            path   string
+            status int
+            body   string
+        }{
+            {"/hi?name=anna", 201, "hello anna!"},
+            {"/missing", 404, "nope"},
+            {"/bad", 500, "Internal Server Error\n"},
+            {"/status", 500, "Internal Server Error\n"},
+        }
+
+        for _, tt := range tests {
+            resp, err := http.Post("http://" + ln.Addr().String() + tt.path, "text/plain",
+                strings.NewReader("!"))
+            if err != nil {
+                t.Fatal(err)
+            }
+            body, _ := io.ReadAll(resp.Body)
+            resp.Body.Close()
+
+            if resp.StatusCode != tt.status || string(body) != tt.body {
+                t.Errorf("%s %s: want %d %q, got %d %q", engine.name, tt.path, tt.status,
+                    tt.body, resp.StatusCode, body)
+            }
+            if tt.status == 201 && resp.Header.Get("X-Greeting") != "hello" {
+                t.Errorf("%s %s: wrong X-Greeting header. got=%q", engine.name, tt.path,
+                    resp.Header.Get("X-Greeting"))
+            }
+        }
+
+        ln.Close()
+        if result := <-done; result != object.NULL {
+            t.Errorf("%s: serve should return null once the listener is closed. got=%+v",
+                engine.name, result)
+        }
+
+        if !strings.Contains(stderr.String(), "/bad: handler returned INTEGER, want HASH or STRING") {
+            t.Errorf("%s: handler error not reported. got=%q", engine.name, stderr.String())
+        }
+        if !strings.Contains(stderr.String(), "/status: status must be in 100..999, got 1000") {
+            t.Errorf("%s: status error not reported. got=%q", engine.name, stderr.String())
+        }
+    }
+}
+
+// every request gets a host of its own, run with -race to see that the
+// handlers share nothing they write to
+func TestServeConcurrentRequests(t *testing.T) {
+    input := `serve(":0", fn(req) {
+        let n = random(1000);
+        puts(n);
+        read_line();
+        str(n)
+    })`
+
+    for _, engine := range engines {
+        var stdout bytes.Buffer
+        ln, done := startServe(t, func(t *testing.T, input string, host *object.Host) object.Object {
+            host.Stdout = &stdout
+            host.Stdin  = strings.NewReader("a line\n")
+            return engine.run(t, input, host)
+        }, input, io.Discard)
+
+        var wg sync.WaitGroup
+        for i := 0; i < 20; i++ {
+            wg.Add(1)
+            go func() {
+                defer wg.Done()
+
+                resp, err := http.Get("http://" + ln.Addr().String() + "/")
+                if err != nil {
+                    t.Error(err)
+                    return
+                }
+                resp.Body.Close()
+
+                if resp.StatusCode != 200 {
+                    t.Errorf("%s: wrong status. want=200, got=%d", engine.name, resp.StatusCode)
+                }
+            }()
+        }
+        wg.Wait()
+
+        ln.Close()
+        <-done
+
+        if lines := strings.Count(stdout.String(), "\n"); lines != 20 {
+            t.Errorf("%s: wrong number of lines printed. want=20, got=%d", engine.name, lines)
+        }
+    }
+}
+
+func TestServeExit(t *testing.T) {
+    for _, engine := range engines {
+        ln, done := startServe(t, engine.run, `serve(":0", fn(req) { exit(3) }); 1`, io.Discard)
+
+        resp, err := http.Get("http://" + ln.Addr().String() + "/")
+        if err != nil {
+            t.Fatal(err)
+        }
+        resp.Body.Close()
+
+        if resp.StatusCode != http.StatusServiceUnavailable {
+            t.Errorf("%s: wrong status. want=503, got=%d", engine.name, resp.StatusCode)
+        }
+
+        result := <-done
+        exit, ok := result.(*object.Exit)
+        if !ok || exit.Code != 3 {
+            t.Errorf("%s: the script should exit with 3. got=%+v", engine.name, result)
+        }
+    }
+}
@@ -31,6 +31,7 @@ var precedences = map[token.TokenType]int{
     token.ASTERISK: PRODUCT,
     token.LPAREN:   CALL,
     token.LBRACKET: INDEX,
+    token.DOT:      INDEX,
 }
 
 type (
@@ -81,6 +82,7 @@ func New(l *lexer.Lexer) *Parser {
     p.registerInfix(token.GT,          p.parseInfixExpression)
     p.registerInfix(token.LPAREN,      p.parseCallExpression)
     p.registerInfix(token.LBRACKET,    p.parseIndexExpression)
+    p.registerInfix(token.DOT,         p.parseFieldExpression)
 
     p.nextToken()
     p.nextToken()
@@ -438,6 +440,10 @@ func (p *Parser) parseInfixExpression(left ast.Expression) ast.Expression {
 }
 
 func (p *Parser) parseCallExpression(function ast.Expression) ast.Expression {
+    if field, ok := function.(*ast.FieldExpression); ok {
+        return p.parseMethodCallExpression(field)
+    }
+
     exp := &ast.CallExpression{
         Token:     p.curToken,
         Function:  function,
@@ -448,11 +454,51 @@ func (p *Parser) parseCallExpression(function ast.Expression) ast.Expression {
     return exp
 }
 
+func (p *Parser) parseMethodCallExpression(field *ast.FieldExpression) ast.Expression {
+    exp := &ast.MethodCallExpression{
+        Token:     p.curToken,
+        Receiver:  field.Left,
+        Method:    field.Field,
+    }
+
+    exp.Arguments = p.parseExpressionList(token.RPAREN)
+
+    return exp
+}
+
 func (p *Parser) parseIndexExpression(left ast.Expression) ast.Expression {
-    exp := &ast.IndexExpression{Token: p.curToken, Left: left}
+    tok := p.curToken
+
+    var index ast.Expression
+    if !p.peekTokenIs(token.COLON) {
+        p.nextToken()
+        index = p.parseExpression(LOWEST)
+    }
+
+    if p.peekTokenIs(token.COLON) {
+        return p.parseSliceExpression(tok, left, index)
+    }
+
+    if !p.expectPeek(token.RBRACKET) {
+        return nil
+    }
+
+    return &ast.IndexExpression{Token: tok, Left: left, Index: index}
+}
+
+// continues after the low bound (if any) of a[low:high], with the ':' as
+// the peek token
+func (p *Parser) parseSliceExpression(tok token.Token,
+    left, low ast.Expression) ast.Expression {
+
+    exp := &ast.SliceExpression{Token: tok, Left: left, Low: low}
 
     p.nextToken()
-    exp.Index = p.parseExpression(LOWEST)
+
+    if !p.peekTokenIs(token.RBRACKET) {
+        p.nextToken()
+        exp.High = p.parseExpression(LOWEST)
+    }
 
     if !p.expectPeek(token.RBRACKET) {
         return nil
@@ -461,6 +507,18 @@ func (p *Parser) parseIndexExpression(left ast.Expression) ast.Expression {
     return exp
 }
 
+func (p *Parser) parseFieldExpression(left ast.Expression) ast.Expression {
+    exp := &ast.FieldExpression{Token: p.curToken, Left: left}
+
+    if !p.expectPeek(token.IDENT) {
+        return nil
+    }
+
+    exp.Field = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+
+    return exp
+}
+
 func (p *Parser) parseExpressionList(end token.TokenType) []ast.Expression {
     list := []ast.Expression{}
 
@@ -488,6 +488,14 @@ func TestOperatorPrecedenceParsing(t *testing.T) {
             "add(a * b[2], b[1], 2 * [1, 2][1])",
             "add((a * (b[2])), (b[1]), (2 * ([1, 2][1])))",
         },
+        {
+            "-a.b * c.d",
+            "((-(a.b)) * (c.d))",
+        },
+        {
+            "a.b.c[0].d",
+            "((((a.b).c)[0]).d)",
+        },
     }
 
     for _, tt := range tests {
@@ -1013,3 +1021,94 @@ func TestParsingIndexExpressions(t *testing.T) {
         return
     }
 }
+
+func TestParsingSliceExpressions(t *testing.T) {
+    tests := []struct {
+        input    string
+        expected string
+    }{
+        {"a[1:2]", "(a[1:2])"},
+        {"a[:2]", "(a[:2])"},
+        {"a[1:]", "(a[1:])"},
+        {"a[:]", "(a[:])"},
+        {"a[:-1]", "(a[:(-1)])"},
+        {"a[i + 1:len(a)]", "(a[(i + 1):len(a)])"},
+        {"a[1:2][0]", "((a[1:2])[0])"},
+        {"a[0][1:]", "((a[0])[1:])"},
+    }
+
+    for _, tt := range tests {
+        l := lexer.New(tt.input)
+        p := New(l)
+        program := p.ParseProgram()
+        checkParserErrors(t, p)
+
+        if program.String() != tt.expected {
+            t.Errorf("expected=%q, got=%q", tt.expected, program.String())
+        }
+    }
+}
+
+func TestParsingFieldExpressions(t *testing.T) {
+    input := "person.name"
+
+    l := lexer.New(input)
+    p := New(l)
+    program := p.ParseProgram()
+    checkParserErrors(t, p)
+
+    stmt, ok := program.Statements[0].(*ast.ExpressionStatement)
+    fieldExp, ok := stmt.Expression.(*ast.FieldExpression)
+    if !ok {
+        t.Fatalf("exp not *ast.FieldExpression. got=%T", stmt.Expression)
+    }
+
+    if !testIdentifier(t, fieldExp.Left, "person") {
+        return
+    }
+
+    if !testIdentifier(t, fieldExp.Field, "name") {
+        return
+    }
+}
+
+func TestParsingMethodCallExpressions(t *testing.T) {
+    tests := []struct {
+        input    string
+        expected string
+    }{
+        {"a.len()", "a.len()"},
+        {"a.push(1, 2 * 3)", "a.push(1, (2 * 3))"},
+        {"a.push(1).len()", "a.push(1).len()"},
+        {"a.b.c(1)", "(a.b).c(1)"},
+        {"a.f()[0]", "(a.f()[0])"},
+    }
+
+    for _, tt := range tests {
+        l := lexer.New(tt.input)
+        p := New(l)
+        program := p.ParseProgram()
+        checkParserErrors(t, p)
+
+        stmt, ok := program.Statements[0].(*ast.ExpressionStatement)
+        if !ok {
+            t.Fatalf("program.Statements[0] is not ast.ExpressionStatement. got=%T",
+                program.Statements[0])
+        }
+
+        if stmt.Expression.String() != tt.expected {
+            t.Errorf("expected=%q, got=%q", tt.expected, stmt.Expression.String())
+        }
+    }
+}
+
+func TestParsingFieldExpressionErrors(t *testing.T) {
+    l := lexer.New("person.1")
+    p := New(l)
+    p.ParseProgram()
+
+    errors := p.Errors()
+    if len(errors) == 0 || errors[0] != "expected next token to be IDENT, got INT instead" {
+        t.Errorf("unexpected parser errors: %v", errors)
+    }
+}
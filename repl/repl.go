@@ -15,17 +15,15 @@ import (
 const PROMPT = ">>"
 
 func Evaluate(in io.Reader, out io.Writer) {
-    scanner := bufio.NewScanner(in)
-    env     := object.NewEnvironment()
+    host := newHost(in, out)
+    env  := object.NewEnvironmentWithHost(host)
 
     for {
-        fmt.Printf(PROMPT)
-        scanned := scanner.Scan()
-        if !scanned {
+        io.WriteString(out, PROMPT)
+        line, ok := readLine(host)
+        if !ok {
             return
         }
-        
-        line := scanner.Text();
 
         l := lexer.New(line)
         p := parser.New(l)
@@ -37,6 +35,9 @@ func Evaluate(in io.Reader, out io.Writer) {
         }
 
         evaluated := evaluator.Eval(program, env)
+        if _, ok := evaluated.(*object.Exit); ok {
+            return
+        }
         if evaluated != nil {
             io.WriteString(out, evaluated.Inspect())
             io.WriteString(out, "\n")
@@ -45,7 +46,7 @@ func Evaluate(in io.Reader, out io.Writer) {
 }
 
 func VM(in io.Reader, out io.Writer) {
-    scanner := bufio.NewScanner(in)
+    host := newHost(in, out)
 
     constants := []object.Object{}
     globals := make([]object.Object, vm.GlobalsSize)
@@ -56,14 +57,12 @@ func VM(in io.Reader, out io.Writer) {
     }
 
     for {
-        fmt.Printf(PROMPT)
-        scanned := scanner.Scan()
-        if !scanned {
+        io.WriteString(out, PROMPT)
+        line, ok := readLine(host)
+        if !ok {
             return
         }
 
-        line := scanner.Text();
-
         l := lexer.New(line)
         p := parser.New(l)
     
@@ -79,12 +78,16 @@ func VM(in io.Reader, out io.Writer) {
             fmt.Fprintf(out, "Woops! Compilation failed:\n %s\n", err)
             continue
         }
+        printWarnings(out, compiler.Warnings())
 
         code := compiler.Bytecode()
         constants = code.Constants
 
-        machine := vm.NewWithGlobalsStore(code, globals)
+        machine := vm.NewWithState(code, globals, host)
         err = machine.Run()
+        if _, ok := err.(*vm.ExitError); ok {
+            return
+        }
         if err != nil {
             fmt.Fprintf(out, "Woops! Executing bytecode failed:\n %s\n", err)
             continue
@@ -96,6 +99,25 @@ func VM(in io.Reader, out io.Writer) {
     }
 }
 
+// scripts share the REPL's input and output, so that puts and read_line
+// work against the same streams the REPL itself uses
+func newHost(in io.Reader, out io.Writer) *object.Host {
+    host := object.NewHost()
+    host.Stdin  = bufio.NewReader(in)
+    host.Stdout = out
+    host.Stderr = out
+    return host
+}
+
+func readLine(host *object.Host) (string, bool) {
+    line, err := host.ReadLine()
+    if err != nil {
+        return "", false
+    }
+
+    return line, true
+}
+
 const MONKEY_FACE = `
             __,__
    .--.  .-"     "-.  .--.
@@ -110,6 +132,12 @@ const MONKEY_FACE = `
            '-----'
 `
 
+func printWarnings(out io.Writer, warnings []string) {
+    for _, msg := range warnings {
+        io.WriteString(out, "warning: "+msg+"\n")
+    }
+}
+
 func printParserErrors(out io.Writer, errors []string) {
     io.WriteString(out, MONKEY_FACE)
     io.WriteString(out, "Woops! We ran into some monkey business here!\n")
@@ -0,0 +1,113 @@
+package repl
+
+import (
+    "bytes"
+    "os"
+    "path/filepath"
+    "strings"
+    "testing"
+    "myMonkey/object"
+)
+
+func TestREPLWritesToOut(t *testing.T) {
+    input := `puts("hi"); 1 + 2
+print("name? "); read_line()
+monkey
+`
+    expected := ">>hi\n3\n>>name? monkey\n>>"
+
+    for name, run := range map[string]func(*strings.Reader, *bytes.Buffer){
+        "Evaluate": func(in *strings.Reader, out *bytes.Buffer) { Evaluate(in, out) },
+        "VM":       func(in *strings.Reader, out *bytes.Buffer) { VM(in, out) },
+    } {
+        var out bytes.Buffer
+        run(strings.NewReader(input), &out)
+
+        if out.String() != expected {
+            t.Errorf("%s: wrong output. want=%q, got=%q", name, expected, out.String())
+        }
+    }
+}
+
+func TestRun(t *testing.T) {
+    tests := []struct {
+        input    string
+        status   int
+        stdout   string
+        stderr   string
+    }{
+        {`puts(args()[0], env("LANG"))`, 0, "x\nmonkey\n", ""},
+        {`puts("a"); exit(3); puts("b")`, 3, "a\n", ""},
+        {`let f = fn() { exit(0) }; f(); puts("b")`, 0, "", ""},
+        {`1 + true`, 1, "", "Woops! Executing bytecode failed:\n unsupported types for binary operation: INTEGER BOOLEAN\n"},
+    }
+
+    for _, tt := range tests {
+        var stdout, stderr bytes.Buffer
+
+        host := object.NewHost()
+        host.Stdout = &stdout
+        host.Stderr = &stderr
+        host.Args   = []string{"x"}
+        host.Env    = map[string]string{"LANG": "monkey"}
+
+        status := Run(tt.input, host)
+        if status != tt.status {
+            t.Errorf("%s: wrong status. want=%d, got=%d", tt.input, tt.status, status)
+        }
+        if stdout.String() != tt.stdout {
+            t.Errorf("%s: wrong stdout. want=%q, got=%q", tt.input, tt.stdout, stdout.String())
+        }
+        if stderr.String() != tt.stderr {
+            t.Errorf("%s: wrong stderr. want=%q, got=%q", tt.input, tt.stderr, stderr.String())
+        }
+    }
+}
+
+func TestRunFile(t *testing.T) {
+    path := filepath.Join(t.TempDir(), "script.monkey")
+    err := os.WriteFile(path, []byte(`exit(len(args()))`), 0644)
+    if err != nil {
+        t.Fatal(err)
+    }
+
+    host := object.NewHost()
+    host.Args = []string{"a", "b"}
+
+    if status := RunFile(path, host); status != 2 {
+        t.Errorf("wrong status. want=2, got=%d", status)
+    }
+
+    var stderr bytes.Buffer
+    host.Stderr = &stderr
+
+    if status := RunFile(path + ".missing", host); status != 1 || stderr.Len() == 0 {
+        t.Errorf("missing file: status=%d, stderr=%q", status, stderr.String())
+    }
+}
+
+func TestREPLStopsOnExit(t *testing.T) {
+    expected := ">>1\n>>"
+
+    for name, run := range map[string]func(*strings.Reader, *bytes.Buffer){
+        "Evaluate": func(in *strings.Reader, out *bytes.Buffer) { Evaluate(in, out) },
+        "VM":       func(in *strings.Reader, out *bytes.Buffer) { VM(in, out) },
+    } {
+        var out bytes.Buffer
+        run(strings.NewReader("1\nexit(0)\n2\n"), &out)
+
+        if out.String() != expected {
+            t.Errorf("%s: wrong output. want=%q, got=%q", name, expected, out.String())
+        }
+    }
+}
+
+func TestVMPrintsWarnings(t *testing.T) {
+    var out bytes.Buffer
+    VM(strings.NewReader("if (false) { 1 } else { 2 }\n"), &out)
+
+    expected := ">>warning: unreachable branch: condition false is always false\n2\n>>"
+    if out.String() != expected {
+        t.Errorf("wrong output. want=%q, got=%q", expected, out.String())
+    }
+}
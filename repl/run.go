@@ -0,0 +1,59 @@
+package repl
+
+import (
+    "errors"
+    "fmt"
+    "os"
+    "myMonkey/lexer"
+    "myMonkey/parser"
+    "myMonkey/object"
+    "myMonkey/compiler"
+    "myMonkey/vm"
+)
+
+// RunFile runs the script at path on the VM and returns the status the
+// process should exit with
+func RunFile(path string, host *object.Host) int {
+    source, err := os.ReadFile(path)
+    if err != nil {
+        fmt.Fprintf(host.Stderr, "%s\n", err)
+        return 1
+    }
+
+    return Run(string(source), host)
+}
+
+// Run compiles and runs source on the VM. Problems are reported on
+// host.Stderr and give status 1, exit(code) gives code.
+func Run(source string, host *object.Host) int {
+    l := lexer.New(source)
+    p := parser.New(l)
+
+    program := p.ParseProgram()
+    if len(p.Errors()) != 0 {
+        printParserErrors(host.Stderr, p.Errors())
+        return 1
+    }
+
+    comp := compiler.New()
+    err := comp.Compile(program)
+    if err != nil {
+        fmt.Fprintf(host.Stderr, "Woops! Compilation failed:\n %s\n", err)
+        return 1
+    }
+    printWarnings(host.Stderr, comp.Warnings())
+
+    machine := vm.NewWithHost(comp.Bytecode(), host)
+    err = machine.Run()
+
+    var exit *vm.ExitError
+    if errors.As(err, &exit) {
+        return exit.Code
+    }
+    if err != nil {
+        fmt.Fprintf(host.Stderr, "Woops! Executing bytecode failed:\n %s\n", err)
+        return 1
+    }
+
+    return 0
+}
@@ -29,6 +29,7 @@ const (
     COMMA       = ","
     SEMICOLON   = ";"
     COLON       = ":"
+    DOT         = "."
 
     LPAREN      = "("
     RPAREN      = ")"
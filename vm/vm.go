@@ -1,6 +1,8 @@
 package vm
 
 import (
+    "bytes"
+    "errors"
     "fmt"
     "myMonkey/code"
     "myMonkey/compiler"
@@ -11,9 +13,18 @@ const StackSize   = 2048
 const GlobalsSize = 65536
 const MaxFrames   = 1024
 
-var True  = &object.Boolean{Value: true}
-var False = &object.Boolean{Value: false}
-var Null  = &object.Null{}
+var True  = object.TRUE
+var False = object.FALSE
+var Null  = object.NULL
+
+// ExitError is what Run returns when the script calls exit
+type ExitError struct {
+    Code int
+}
+
+func (e *ExitError) Error() string {
+    return fmt.Sprintf("exit status %d", e.Code)
+}
 
 type VM struct {
     constants      []object.Object
@@ -26,9 +37,27 @@ type VM struct {
     framesIndex    int
 
     globals        []object.Object
+
+    host           *object.Host
 }
 
 func New(bytecode *compiler.Bytecode) *VM {
+    return newVM(bytecode, make([]object.Object, GlobalsSize), object.NewHost())
+}
+
+func NewWithGlobalsStore(bytecode *compiler.Bytecode, s []object.Object) *VM {
+    return newVM(bytecode, s, object.NewHost())
+}
+
+func NewWithHost(bytecode *compiler.Bytecode, h *object.Host) *VM {
+    return newVM(bytecode, make([]object.Object, GlobalsSize), h)
+}
+
+func NewWithState(bytecode *compiler.Bytecode, s []object.Object, h *object.Host) *VM {
+    return newVM(bytecode, s, h)
+}
+
+func newVM(bytecode *compiler.Bytecode, globals []object.Object, host *object.Host) *VM {
     mainFn    := &object.CompiledFunction{Instructions: bytecode.Instructions}
     mainFrame := NewFrame(mainFn, 0)
 
@@ -42,26 +71,28 @@ func New(bytecode *compiler.Bytecode) *VM {
         stack:        make([]object.Object, StackSize),
         sp:           0,
 
-        globals:      make([]object.Object, GlobalsSize),
+        globals:      globals,
 
         frames:       frames,
         framesIndex:  1,
-    }
-}
 
-func NewWithGlobalsStore(bytecode *compiler.Bytecode, s []object.Object) *VM {
-    vm := New(bytecode)
-    vm.globals = s
-    return vm
+        host:         host,
+    }
 }
 
 func (vm *VM) currentFrame() *Frame {
     return vm.frames[vm.framesIndex - 1]
 }
 
-func (vm *VM)  pushFrame(f *Frame) {
+func (vm *VM)  pushFrame(f *Frame) error {
+    if vm.framesIndex >= MaxFrames {
+        return fmt.Errorf("stack overflow: more than %d nested calls", MaxFrames)
+    }
+
     vm.frames[vm.framesIndex] = f
     vm.framesIndex++
+
+    return nil
 }
 
 func (vm *VM) popFrame() *Frame {
@@ -84,25 +115,25 @@ func (vm *VM) Run() error {
 
             err := vm.push(vm.constants[constIdx])
             if err != nil {
-                return nil
+                return err
             }
 
         case code.OpTrue:
             err := vm.push(True)
             if err != nil {
-                return nil
+                return err
             }
 
         case code.OpFalse:
             err := vm.push(False)
             if err != nil {
-                return nil
+                return err
             }
 
         case code.OpNull:
             err := vm.push(Null)
             if err != nil {
-                return nil
+                return err
             }
 
         case code.OpAdd, code.OpSub, code.OpMul, code.OpDiv:
@@ -111,6 +142,15 @@ func (vm *VM) Run() error {
                 return err
             }
 
+        case code.OpAddConst:
+            constIndex := code.ReadUint16(ins[ip+1:])
+            vm.currentFrame().ip += 2
+
+            err := vm.executeAddConst(vm.constants[constIndex])
+            if err != nil {
+                return err
+            }
+
         case code.OpEqual, code.OpNotEqual, code.OpLessThan, code.OpGreaterThan:
             err := vm.executeComparison(op)
             if err != nil {
@@ -145,6 +185,18 @@ func (vm *VM) Run() error {
                 vm.currentFrame().ip = pos - 1
             }
 
+        case code.OpLessThanJump:
+            pos := int(code.ReadUint16(ins[ip+1:]))
+            vm.currentFrame().ip += 2
+
+            less, err := vm.executeLessThan()
+            if err != nil {
+                return err
+            }
+            if !less {
+                vm.currentFrame().ip = pos - 1
+            }
+
         case code.OpSetGlobal:
             globalIndex := code.ReadUint16(ins[ip+1:])
             vm.currentFrame().ip += 2
@@ -179,6 +231,14 @@ func (vm *VM) Run() error {
                 return err
             }
 
+        case code.OpGetLocal0, code.OpGetLocal1, code.OpGetLocal2, code.OpGetLocal3:
+            frame := vm.currentFrame()
+
+            err := vm.push(vm.stack[frame.basePointer + int(op - code.OpGetLocal0)])
+            if err != nil {
+                return err
+            }
+
         case code.OpGetBuiltin:
             builtinIndex := code.ReadUint8(ins[ip+1:])
             vm.currentFrame().ip += 1
@@ -226,6 +286,16 @@ func (vm *VM) Run() error {
                 return err
             }
 
+        case code.OpSlice:
+            high := vm.pop()
+            low  := vm.pop()
+            left := vm.pop()
+
+            err := vm.executeSliceExpression(left, low, high)
+            if err != nil {
+                return err
+            }
+
         case code.OpCall:
             numArgs := code.ReadUint8(ins[ip+1:])
             vm.currentFrame().ip += 1
@@ -235,6 +305,33 @@ func (vm *VM) Run() error {
                 return err
             }
 
+        case code.OpCall0, code.OpCall1:
+            err := vm.executeCall(int(op - code.OpCall0))
+            if err != nil {
+                return err
+            }
+
+        case code.OpTailCall:
+            numArgs := code.ReadUint8(ins[ip+1:])
+            vm.currentFrame().ip += 1
+
+            err := vm.executeTailCall(int(numArgs))
+            if err != nil {
+                return err
+            }
+
+        case code.OpCallMethod:
+            nameIndex := code.ReadUint16(ins[ip+1:])
+            numArgs   := code.ReadUint8(ins[ip+3:])
+            vm.currentFrame().ip += 3
+
+            name := vm.constants[nameIndex].(*object.String)
+
+            err := vm.executeMethodCall(name, int(numArgs))
+            if err != nil {
+                return err
+            }
+
         case code.OpReturnValue:
             // the return value sits on top of the stack
             returnValue := vm.pop()
@@ -279,12 +376,32 @@ func (vm *VM) executeBinaryOperation(op code.Opcode) error {
         return vm.executeBinaryIntegerOperation(op, left, right)
     case lType == object.STRING_OBJ && rType == object.STRING_OBJ:
         return vm.executeBinaryStringOperation(op, left, right)
+    case lType == object.BYTES_OBJ && rType == object.BYTES_OBJ:
+        return vm.executeBinaryBytesOperation(op, left, right)
     default:
         return fmt.Errorf("unsupported types for binary operation: %s %s",
         lType, rType)
     }
 }
 
+// OpAddConst adds right to the top of the stack in place when both are
+// integers, anything else takes the OpConstant, OpAdd route
+func (vm *VM) executeAddConst(right object.Object) error {
+    leftValue, lok  := vm.stack[vm.sp - 1].(*object.Integer)
+    rightValue, rok := right.(*object.Integer)
+    if lok && rok {
+        vm.stack[vm.sp - 1] = object.NewInteger(leftValue.Value + rightValue.Value)
+        return nil
+    }
+
+    err := vm.push(right)
+    if err != nil {
+        return err
+    }
+
+    return vm.executeBinaryOperation(code.OpAdd)
+}
+
 func (vm *VM) executeBinaryIntegerOperation(
     op code.Opcode,
     left, right object.Object,
@@ -307,7 +424,7 @@ func (vm *VM) executeBinaryIntegerOperation(
         return fmt.Errorf("unknown integer operator: %d", op)
     }
 
-    return vm.push(&object.Integer{Value: result})
+    return vm.push(object.NewInteger(result))
 }
 
 func (vm *VM) executeBinaryStringOperation(
@@ -329,6 +446,24 @@ func (vm *VM) executeBinaryStringOperation(
     return vm.push(&object.String{Value: result})
 }
 
+func (vm *VM) executeBinaryBytesOperation(
+    op code.Opcode,
+    left, right object.Object,
+) error {
+    leftValue  := left.(*object.Bytes).Value
+    rightValue := right.(*object.Bytes).Value
+
+    if op != code.OpAdd {
+        return fmt.Errorf("unknown bytes operator: %d", op)
+    }
+
+    result := make([]byte, 0, len(leftValue) + len(rightValue))
+    result  = append(result, leftValue...)
+    result  = append(result, rightValue...)
+
+    return vm.push(&object.Bytes{Value: result})
+}
+
 func (vm *VM) executeComparison(op code.Opcode) error {
     right := vm.pop()
     left := vm.pop()
@@ -337,6 +472,13 @@ func (vm *VM) executeComparison(op code.Opcode) error {
         return vm.executeIntegerComparison(op, left, right)
     }
 
+    // strings and bytes compare by value, whether two of them are one
+    // object depends on constant interning. Sets compare by their elements
+    // as in the evaluator.
+    if equal, ok := valuesEqual(left, right); ok && (op == code.OpEqual || op == code.OpNotEqual) {
+        return vm.push(nativeBoolToBooleanObject(equal == (op == code.OpEqual)))
+    }
+
     switch op {
     case code.OpEqual:
         return vm.push(nativeBoolToBooleanObject(right == left))
@@ -348,6 +490,22 @@ func (vm *VM) executeComparison(op code.Opcode) error {
     }
 }
 
+func valuesEqual(left, right object.Object) (bool, bool) {
+    switch left := left.(type) {
+    case *object.String:
+        right, ok := right.(*object.String)
+        return ok && left.Value == right.Value, true
+    case *object.Bytes:
+        right, ok := right.(*object.Bytes)
+        return ok && bytes.Equal(left.Value, right.Value), true
+    case *object.Set:
+        right, ok := right.(*object.Set)
+        return ok && left.Equal(right), true
+    }
+
+    return false, false
+}
+
 func (vm *VM) executeIntegerComparison(
     op code.Opcode,
     left, right object.Object,
@@ -369,6 +527,24 @@ func (vm *VM) executeIntegerComparison(
     }
 }
 
+// the comparison of OpLessThanJump, without pushing a boolean on the fast
+// path
+func (vm *VM) executeLessThan() (bool, error) {
+    leftValue, lok  := vm.stack[vm.sp - 2].(*object.Integer)
+    rightValue, rok := vm.stack[vm.sp - 1].(*object.Integer)
+    if lok && rok {
+        vm.sp -= 2
+        return leftValue.Value < rightValue.Value, nil
+    }
+
+    err := vm.executeComparison(code.OpLessThan)
+    if err != nil {
+        return false, err
+    }
+
+    return isTruthy(vm.pop()), nil
+}
+
 func (vm *VM) executeBangOperator() error {
     operand := vm.pop()
 
@@ -392,16 +568,21 @@ func (vm *VM) executeMinusOperator() error {
     }
 
     value := operand.(*object.Integer).Value
-    return vm.push(&object.Integer{Value: -value})
+    return vm.push(object.NewInteger(-value))
 }
 
 func (vm *VM) executeIndexExpression(left, index object.Object) error {
+    if field, ok := index.(*object.FieldKey); ok {
+        return vm.executeFieldAccess(left, field)
+    }
+
     lType := left.Type()
     iType := index.Type()
 
     switch {
-    case lType == object.ARRAY_OBJ && iType == object.INTEGER_OBJ:
-        return vm.executeArrayIndex(left, index)
+    case (lType == object.ARRAY_OBJ || lType == object.STRING_OBJ || lType == object.BYTES_OBJ) &&
+        iType == object.INTEGER_OBJ:
+        return vm.push(object.IndexSequence(left, index.(*object.Integer).Value))
     case lType == object.HASH_OBJ:
         return vm.executeHashIndex(left, index)
     default:
@@ -409,16 +590,13 @@ func (vm *VM) executeIndexExpression(left, index object.Object) error {
     }
 }
 
-func (vm *VM) executeArrayIndex(left, index object.Object) error {
-    array := left.(*object.Array)
-    i     := index.(*object.Integer).Value
-
-    max   := int64(len(array.Elements) - 1)
-    if i < 0 || i > max {
-        return vm.push(Null)
+func (vm *VM) executeSliceExpression(left, low, high object.Object) error {
+    result, err := object.Slice(left, low, high)
+    if err != nil {
+        return err
     }
 
-    return vm.push(array.Elements[i])
+    return vm.push(result)
 }
 
 func (vm *VM) executeHashIndex(left, index object.Object) error {
@@ -436,6 +614,21 @@ func (vm *VM) executeHashIndex(left, index object.Object) error {
     return vm.push(pair.Value)
 }
 
+// unlike indexing, reading a field that isn't there is an error
+func (vm *VM) executeFieldAccess(left object.Object, field *object.FieldKey) error {
+    hash, ok := left.(*object.Hash)
+    if !ok {
+        return fmt.Errorf("field access not supported: %s", left.Type())
+    }
+
+    pair, ok := hash.Pairs[field.HashKey()]
+    if !ok {
+        return fmt.Errorf("unknown field: %s", field.Name)
+    }
+
+    return vm.push(pair.Value)
+}
+
 func (vm *VM) push(o object.Object) error {
     if vm.sp >= StackSize {
         return fmt.Errorf("stack overflow")
@@ -526,6 +719,62 @@ func (vm *VM) executeCall(numArgs int) error {
     }
 }
 
+// the stack holds the receiver, the fallback function (or Null) and the
+// arguments. A hash field of that name wins, otherwise receiver.f(args)
+// is f(receiver, args). Either way the stack is rearranged into a plain
+// call.
+func (vm *VM) executeMethodCall(name *object.String, numArgs int) error {
+    receiverPos := vm.sp - numArgs - 2
+    receiver    := vm.stack[receiverPos]
+    fallback    := vm.stack[receiverPos + 1]
+
+    if hash, ok := receiver.(*object.Hash); ok {
+        if pair, ok := hash.Pairs[name.HashKey()]; ok {
+            // [field, args...]
+            vm.stack[receiverPos] = pair.Value
+            copy(vm.stack[receiverPos + 1:], vm.stack[receiverPos + 2 : vm.sp])
+            vm.sp--
+
+            return vm.executeCall(numArgs)
+        }
+    }
+
+    if fallback == Null {
+        return fmt.Errorf("unknown method: %s", name.Value)
+    }
+
+    // [fallback, receiver, args...]
+    vm.stack[receiverPos], vm.stack[receiverPos + 1] = fallback, receiver
+
+    return vm.executeCall(numArgs + 1)
+}
+
+// a tail call to a compiled function takes over the frame and the stack
+// window of the caller, which has nothing left to do but return what the
+// callee returns. Builtins don't need a frame and are called as usual.
+func (vm *VM) executeTailCall(numArgs int) error {
+    fn, ok := vm.stack[vm.sp - 1 - numArgs].(*object.CompiledFunction)
+    if !ok {
+        return vm.executeCall(numArgs)
+    }
+
+    if numArgs != fn.NumParameters {
+        return fmt.Errorf("wrong number of arguments: want=%d, got=%d",
+            fn.NumParameters, numArgs)
+    }
+
+    frame := vm.currentFrame()
+
+    // the callee and its arguments replace the caller and its arguments
+    copy(vm.stack[frame.basePointer - 1:], vm.stack[vm.sp - 1 - numArgs : vm.sp])
+
+    frame.fn = fn
+    frame.ip = -1
+    vm.sp    = frame.basePointer + fn.NumLocals
+
+    return nil
+}
+
 func (vm *VM) callFunction(fn *object.CompiledFunction, numArgs int) error {
     if numArgs != fn.NumParameters {
         return fmt.Errorf("wrong number of arguments: want=%d, got=%d",
@@ -533,7 +782,10 @@ func (vm *VM) callFunction(fn *object.CompiledFunction, numArgs int) error {
     }
 
     frame := NewFrame(fn, vm.sp - numArgs)
-    vm.pushFrame(frame)
+    err := vm.pushFrame(frame)
+    if err != nil {
+        return err
+    }
 
     vm.sp = frame.basePointer + fn.NumLocals
 
@@ -543,9 +795,19 @@ func (vm *VM) callFunction(fn *object.CompiledFunction, numArgs int) error {
 func (vm *VM) callBuiltin(builtin *object.Builtin, numArgs int) error {
     args := vm.stack[vm.sp - numArgs : vm.sp]
 
-    result := builtin.Fn(args...)
+    result := builtin.Fn(vm.host, args...)
     vm.sp = vm.sp - numArgs - 1
 
+    if serve, ok := result.(*object.Serve); ok {
+        result = serve.Run(vm.host, func(host *object.Host, request object.Object) object.Object {
+            return vm.callOnNewVM(host, serve.Handler, request)
+        })
+    }
+
+    if exit, ok := result.(*object.Exit); ok {
+        return &ExitError{Code: exit.Code}
+    }
+
     if result != nil {
         vm.push(result)
     } else {
@@ -554,3 +816,30 @@ func (vm *VM) callBuiltin(builtin *object.Builtin, numArgs int) error {
 
     return nil
 }
+
+// calls fn on a VM of its own that shares the constants and the globals
+// of vm, so that fn can run alongside vm and other calls like it. An exit
+// in fn comes back as the Exit object the builtin returned.
+func (vm *VM) callOnNewVM(host *object.Host, fn object.Object, args ...object.Object) object.Object {
+    machine := newVM(&compiler.Bytecode{Constants: vm.constants}, vm.globals, host)
+
+    // the main frame has no instructions, Run stops when fn returns to it
+    for _, obj := range append([]object.Object{fn}, args...) {
+        machine.push(obj)
+    }
+
+    err := machine.executeCall(len(args))
+    if err == nil {
+        err = machine.Run()
+    }
+
+    var exit *ExitError
+    if errors.As(err, &exit) {
+        return &object.Exit{Code: exit.Code}
+    }
+    if err != nil {
+        return &object.Error{Message: err.Error()}
+    }
+
+    return machine.StackTop()
+}
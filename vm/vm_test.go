@@ -1,8 +1,12 @@
 package vm
 
 import (
+    "bytes"
+    "strings"
     "testing"
     "fmt"
+    "math/rand"
+    "time"
     "myMonkey/ast"
     "myMonkey/object"
     "myMonkey/lexer"
@@ -150,7 +154,9 @@ func TestIndexExpressions(t *testing.T) {
         {"[[1, 1, 1]][0][0]", 1},
         {"[][0]", Null},
         {"[1, 2, 3][99]", Null},
-        {"[1][-1]", Null},
+        {"[1][-1]", 1},
+        {"[1, 2, 3][-2]", 2},
+        {"[1, 2, 3][-4]", Null},
         {"{1: 1, 2: 2}[1]", 1},
         {"{1: 1, 2: 2}[2]", 2},
         {"{1: 1}[0]", Null},
@@ -160,6 +166,119 @@ func TestIndexExpressions(t *testing.T) {
     runVmTests(t, tests)
 }
 
+func TestFieldExpressions(t *testing.T) {
+    tests := []vmTestCase{
+        {`let person = {"name": "Anna", "age": 30}; person.name`, "Anna"},
+        {`let person = {"name": "Anna", "age": 30}; person.age + 1`, 31},
+        {`{"a": {"b": [1, 2]}}.a.b[1]`, 2},
+        {`let f = fn(p) { p.x * 2 }; f({"x": 21})`, 42},
+    }
+
+    runVmTests(t, tests)
+}
+
+func TestFieldExpressionErrors(t *testing.T) {
+    tests := []vmTestCase{
+        {`{"a": 1}.b`, "unknown field: b"},
+        {`[1].length`, "field access not supported: ARRAY"},
+    }
+
+    for _, tt := range tests {
+        comp := compiler.New()
+        err := comp.Compile(parse(tt.input))
+        if err != nil {
+            t.Fatalf("compiler error: %s", err)
+        }
+
+        vm := New(comp.Bytecode())
+        err = vm.Run()
+        if err == nil {
+            t.Fatalf("expected VM error but resulted in none.")
+        }
+
+        if err.Error() != tt.expected {
+            t.Errorf("wrong VM error: want=%q, got=%q", tt.expected, err)
+        }
+    }
+}
+
+func TestMethodCalls(t *testing.T) {
+    tests := []vmTestCase{
+        {`[1, 2].push(3).len()`, 3},
+        {`"monkey".len()`, 6},
+        {`let double = fn(x) { x * 2 }; 21.double()`, 42},
+        {`let add = fn(a, b) { a + b }; 1.add(2).add(3)`, 6},
+        {`let counter = {"next": fn(x) { x + 1 }}; counter.next(1)`, 2},
+        {`let len = fn(x) { 0 }; {"len": fn() { 1 }}.len()`, 1},
+        {`let f = fn(xs) { let n = 10; xs.push(n).len() }; f([1])`, 2},
+    }
+
+    runVmTests(t, tests)
+}
+
+func TestMethodCallErrors(t *testing.T) {
+    comp := compiler.New()
+    err := comp.Compile(parse(`1.nope()`))
+    if err != nil {
+        t.Fatalf("compiler error: %s", err)
+    }
+
+    vm := New(comp.Bytecode())
+    err = vm.Run()
+    if err == nil {
+        t.Fatalf("expected VM error but resulted in none.")
+    }
+
+    if err.Error() != "unknown method: nope" {
+        t.Errorf("wrong VM error: want=%q, got=%q", "unknown method: nope", err)
+    }
+}
+
+func TestSliceExpressions(t *testing.T) {
+    tests := []vmTestCase{
+        {`"monkey"[0]`, "m"},
+        {`"monkey"[-1]`, "y"},
+        {`"monkey"[10]`, Null},
+        {`"monkey"[1:3]`, "on"},
+        {`"monkey"[:-3]`, "mon"},
+        {`"monkey"[3:]`, "key"},
+        {`"monkey"[4:2]`, ""},
+        {`[1, 2, 3, 4][1:3]`, []int{2, 3}},
+        {`[1, 2, 3, 4][:-1]`, []int{1, 2, 3}},
+        {`[1, 2, 3, 4][-2:]`, []int{3, 4}},
+        {`[1, 2, 3][:]`, []int{1, 2, 3}},
+        {`[1, 2, 3][-10:10]`, []int{1, 2, 3}},
+        {`let i = 1; [1, 2, 3][i:i + 1]`, []int{2}},
+    }
+
+    runVmTests(t, tests)
+}
+
+func TestSliceErrors(t *testing.T) {
+    tests := []vmTestCase{
+        {`[1, 2]["a":]`, "slice bounds must be INTEGER, got STRING"},
+        {`{}[1:2]`, "slice operator not supported: HASH"},
+    }
+
+    for _, tt := range tests {
+        comp := compiler.New()
+        err := comp.Compile(parse(tt.input))
+        if err != nil {
+            t.Fatalf("compiler error: %s", err)
+        }
+
+        vm := New(comp.Bytecode())
+        err = vm.Run()
+        if err == nil {
+            t.Fatalf("expected VM error but resulted in none.")
+        }
+
+        if err.Error() != tt.expected {
+            t.Errorf("wrong VM error: want=%q, got=%q", tt.expected, err)
+        }
+    }
+}
+
 func TestCallingFunctionsWithoutArguments(t *testing.T) {
     tests := []vmTestCase{
         {
@@ -465,6 +584,594 @@ func TestBuiltinFunctions(t *testing.T) {
     runVmTests(t, tests)
 }
 
+func TestTypeBuiltins(t *testing.T) {
+    tests := []vmTestCase{
+        {`type(1)`, "INTEGER"},
+        {`type("one")`, "STRING"},
+        {`type([1])`, "ARRAY"},
+        {`type({})`, "HASH"},
+        {`type(fn() {})`, "FUNCTION"},
+        {`type(len)`, "BUILTIN"},
+        {`is_int(1)`, true},
+        {`is_string(1)`, false},
+        {`is_array([])`, true},
+        {`is_hash({})`, true},
+        {`is_fn(fn() {})`, true},
+        {`is_fn(len)`, true},
+        {`is_fn("fn")`, false},
+        {`int("42")`, 42},
+        {`int(true)`, 1},
+        {`int("abc")`, &object.Error{Message: `could not convert "abc" to INTEGER`}},
+        {`str(42)`, "42"},
+        {`str([1, 2])`, "[1, 2]"},
+        {`bool(0)`, true},
+        {`bool(false)`, false},
+        {`bool(1) == true`, true},
+        {`if (bool(false)) { 1 } else { 2 }`, 2},
+    }
+
+    runVmTests(t, tests)
+}
+
+func TestMathBuiltins(t *testing.T) {
+    tests := []vmTestCase{
+        {`abs(-5)`, 5},
+        {`min(3, 1, 2)`, 1},
+        {`min([4, -2, 9])`, -2},
+        {`max(3, 1, 2)`, 3},
+        {`max([4, -2, 9])`, 9},
+        {`pow(2, 10)`, 1024},
+        {`sqrt(17)`, 4},
+        {`sqrt(9223372036854775807)`, 3037000499},
+        {`sqrt(3037000499 * 3037000499)`, 3037000499},
+        {`sqrt(-4)`,
+            &object.Error{
+                Message: "square root of negative number: -4",
+            },
+        },
+        {`random(0)`,
+            &object.Error{
+                Message: "empty range for `random`: [0, 0)",
+            },
+        },
+        {`abs(-9223372036854775807 - 1)`,
+            &object.Error{
+                Message: "integer overflow: abs(-9223372036854775808)",
+            },
+        },
+    }
+
+    runVmTests(t, tests)
+}
+
+func TestSeededRandom(t *testing.T) {
+    input := `[random(), random(10), random(-5, 5)]`
+
+    want := rand.New(rand.NewSource(42))
+    expected := []int64{want.Int63(), want.Int63n(10), -5 + want.Int63n(10)}
+
+    for run := 0; run < 2; run++ {
+        host := object.NewHost()
+        host.Seed(42)
+
+        comp := compiler.New()
+        err := comp.Compile(parse(input))
+        if err != nil {
+            t.Fatalf("compiler error: %s", err)
+        }
+
+        vm := NewWithHost(comp.Bytecode(), host)
+        err = vm.Run()
+        if err != nil {
+            t.Fatalf("vm error: %s", err)
+        }
+
+        result, ok := vm.LastPoppedStackElem().(*object.Array)
+        if !ok {
+            t.Fatalf("object is not Array. got=%T", result)
+        }
+
+        for i, e := range expected {
+            err := testIntegerObject(e, result.Elements[i])
+            if err != nil {
+                t.Errorf("testIntegerObject failed: %s", err)
+            }
+        }
+    }
+}
+
+func TestFileBuiltins(t *testing.T) {
+    fs, err := object.NewDirFileSystem(t.TempDir(), true)
+    if err != nil {
+        t.Fatalf("NewDirFileSystem failed: %s", err)
+    }
+    defer fs.Close()
+
+    granted := object.NewHost()
+    granted.FS = fs
+
+    tests := []struct {
+        host     *object.Host
+        input    string
+        expected interface{}
+    }{
+        {object.NewHost(), `read_file("a")`,
+            &object.Error{Message: "read_file: file access denied by host"}},
+        {object.NewHost(), `exists("a")`,
+            &object.Error{Message: "exists: file access denied by host"}},
+        {granted, `exists("a")`, false},
+        {granted, `write_file("a", "b")`,
+            &object.Error{Message: "write_file: file system is read-only"}},
+        {granted, `len(list_dir("."))`, 0},
+    }
+
+    for _, tt := range tests {
+        comp := compiler.New()
+        err := comp.Compile(parse(tt.input))
+        if err != nil {
+            t.Fatalf("compiler error: %s", err)
+        }
+
+        vm := NewWithHost(comp.Bytecode(), tt.host)
+        err = vm.Run()
+        if err != nil {
+            t.Fatalf("vm error: %s", err)
+        }
+
+        testExpectedObject(t, tt.expected, vm.LastPoppedStackElem())
+    }
+}
+
+func TestJsonBuiltins(t *testing.T) {
+    tests := []vmTestCase{
+        {`json_encode([1, "two", false])`, `[1,"two",false]`},
+        {`json_encode({"b": 1, "a": 2})`, `{"a":2,"b":1}`},
+        {`json_encode({"a": 1}, true)`, "{\n  \"a\": 1\n}"},
+        {`json_decode(json_encode({"a": [1, 2]}))["a"][0]`, 1},
+        {`json_encode(fn() { 1 })`,
+            &object.Error{
+                Message: "json_encode: unsupported value COMPILED_FN_OBJ",
+            },
+        },
+        {`json_decode("{")`,
+            &object.Error{
+                Message: "json_decode: unexpected EOF",
+            },
+        },
+    }
+
+    runVmTests(t, tests)
+}
+
+func TestRegexBuiltins(t *testing.T) {
+    tests := []vmTestCase{
+        {`match("(\w+)@(\w+)", "bob@example")[2]`, "example"},
+        {`match("\d+", "abc")`, Null},
+        {`len(find_all("\d", "a1b2c3"))`, 3},
+        {`captures("(?P<user>\w+)@", "bob@example")["user"]`, "bob"},
+        {`replace(regex("o+"), "foo boo", "0")`, "f0 b0"},
+        {`len(split("\s+", "a  b c"))`, 3},
+    }
+
+    runVmTests(t, tests)
+}
+
+func TestTimeBuiltins(t *testing.T) {
+    start := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+    tests := []vmTestCase{
+        {`now() - 1709294400000`, 0},
+        {`let t = now(); sleep(250); now() - t`, 250},
+        {`format_time(now() + duration("1m"), "TimeOnly")`, "12:01:00"},
+        {`parse_time("2024-03-01", "DateOnly") + duration("12h") == now()`, true},
+        {`format_duration(1500)`, "1.5s"},
+    }
+
+    for _, tt := range tests {
+        host := object.NewHost()
+        host.Clock = object.NewManualClock(start)
+
+        comp := compiler.New()
+        err := comp.Compile(parse(tt.input))
+        if err != nil {
+            t.Fatalf("compiler error: %s", err)
+        }
+
+        vm := NewWithHost(comp.Bytecode(), host)
+        err = vm.Run()
+        if err != nil {
+            t.Fatalf("vm error: %s", err)
+        }
+
+        testExpectedObject(t, tt.expected, vm.LastPoppedStackElem())
+    }
+}
+
+func TestOutputBuiltins(t *testing.T) {
+    var stdout bytes.Buffer
+
+    host := object.NewHost()
+    host.Stdout = &stdout
+    host.Stdin  = strings.NewReader("line\n")
+
+    input := `puts(1, "two"); print("x"); print(read_line()); read_line()`
+
+    comp := compiler.New()
+    err := comp.Compile(parse(input))
+    if err != nil {
+        t.Fatalf("compiler error: %s", err)
+    }
+
+    vm := NewWithHost(comp.Bytecode(), host)
+    err = vm.Run()
+    if err != nil {
+        t.Fatalf("vm error: %s", err)
+    }
+
+    if stdout.String() != "1\ntwo\nxline" {
+        t.Errorf("wrong stdout. got=%q", stdout.String())
+    }
+
+    testExpectedObject(t, Null, vm.LastPoppedStackElem())
+}
+
+func TestSetBuiltins(t *testing.T) {
+    tests := []vmTestCase{
+        {`len(set([1, 2, 2, 3, 1]))`, 3},
+        {`to_array(set(3, 1, 3))`, []int{3, 1}},
+        {`let s = add(set(), 4); contains(s, 4)`, true},
+        {`to_array(union(set(1, 2), set(2, 3)))`, []int{1, 2, 3}},
+        {`to_array(intersection(set(1, 2), set(2, 3)))`, []int{2}},
+        {`to_array(difference(set(1, 2), set(2, 3)))`, []int{1}},
+        {`len(remove(set(1, 2), 5))`, 2},
+        {`let s = set(1); s == s`, true},
+        {`set(1, 2) == set(2, 1)`, true},
+        {`set(1, 2) == set(1)`, false},
+        {`set(1) != set(2)`, true},
+        {`set(fn() {})`,
+            &object.Error{
+                Message: "unusable as set element: COMPILED_FN_OBJ",
+            },
+        },
+    }
+
+    runVmTests(t, tests)
+}
+
+func TestBytes(t *testing.T) {
+    tests := []vmTestCase{
+        {`len(bytes("hello"))`, 5},
+        {`bytes("hi")[-1]`, 105},
+        {`bytes("hi")[2]`, Null},
+        {`str(bytes([104, 105]))`, "hi"},
+        {`str(bytes("monkey")[1:3])`, "on"},
+        {`str(bytes("mon") + bytes("key"))`, "monkey"},
+        {`hex_encode(bytes([0, 15, 255]))`, "000fff"},
+        {`str(base64_decode(base64_encode("hello")))`, "hello"},
+        {`{bytes("k"): 1}[bytes("k")]`, 1},
+        {`bytes([-1])`, &object.Error{Message: "byte out of range: -1"}},
+    }
+
+    runVmTests(t, tests)
+}
+
+func TestHashBuiltins(t *testing.T) {
+    tests := []vmTestCase{
+        {`sha256("abc")`, "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad"},
+        {`md5(bytes("abc"))`, "900150983cd24fb0d6963f7d28e17f72"},
+        {`crc32("abc")`, "352441c2"},
+        {`hmac("md5", "key", "msg")`, "18e3548c59ad40dd03907b7aeee71d67"},
+    }
+
+    runVmTests(t, tests)
+}
+
+func TestExit(t *testing.T) {
+    tests := []struct {
+        input    string
+        expected int
+    }{
+        {`exit(3); puts("unreachable")`, 3},
+        {`let f = fn() { exit(1); 2 }; f() + 1`, 1},
+        {`exit()`, 0},
+    }
+
+    for _, tt := range tests {
+        comp := compiler.New()
+        err := comp.Compile(parse(tt.input))
+        if err != nil {
+            t.Fatalf("compiler error: %s", err)
+        }
+
+        vm := New(comp.Bytecode())
+        err = vm.Run()
+
+        exit, ok := err.(*ExitError)
+        if !ok {
+            t.Fatalf("expected *ExitError, got=%T (%v)", err, err)
+        }
+        if exit.Code != tt.expected {
+            t.Errorf("wrong exit code. want=%d, got=%d", tt.expected, exit.Code)
+        }
+    }
+}
+
+func TestProcessBuiltins(t *testing.T) {
+    host := object.NewHost()
+    host.Args = []string{"a", "b"}
+    host.Env  = map[string]string{"USER": "monkey"}
+
+    tests := []vmTestCase{
+        {`len(args())`, 2},
+        {`args()[1]`, "b"},
+        {`env("USER")`, "monkey"},
+        {`env("NOPE")`, Null},
+    }
+
+    for _, tt := range tests {
+        comp := compiler.New()
+        err := comp.Compile(parse(tt.input))
+        if err != nil {
+            t.Fatalf("compiler error: %s", err)
+        }
+
+        vm := NewWithHost(comp.Bytecode(), host)
+        err = vm.Run()
+        if err != nil {
+            t.Fatalf("vm error: %s", err)
+        }
+
+        testExpectedObject(t, tt.expected, vm.LastPoppedStackElem())
+    }
+}
+
+func TestServe(t *testing.T) {
+    host := object.NewHost()
+    host.NetworkDisabled = true
+
+    comp := compiler.New()
+    comp.Compile(parse(`serve(":0", fn(req) { "" })`))
+
+    vm := NewWithHost(comp.Bytecode(), host)
+    vm.Run()
+    testExpectedObject(t, &object.Error{Message: "serve: network access disabled by host"},
+        vm.LastPoppedStackElem())
+}
+
+func TestConstantFolding(t *testing.T) {
+    tests := []vmTestCase{
+        {"2 * 60 * 60", 7200},
+        {"let x = 6; let y = x * 7; y", 42},
+        {"let x = 6; let f = fn(x) { x + 1 }; f(1)", 2},
+        {"let x = 1; let x = x + 1; x", 2},
+        {"if (!false) { 10 } else { 20 }", 10},
+        {"if (1 > 2) { 10 }", Null},
+        {"if (true) { let z = 5; }", Null},
+        {`let s = "mon" + "key"; s`, "monkey"},
+        {"let f = fn() { if (true) { return 1; } 2 }; f()", 1},
+        {"let c = fn() { false }; let x = 1; if (c()) { let x = 2 }; x", 1},
+        {"let c = fn() { true }; let x = 1; if (c()) { let x = 2 }; x", 2},
+        {"let f = fn(c) { let y = 1; if (c) { let y = 2 }; y }; [f(false), f(true)]", []int{1, 2}},
+        {"let x = 1; let f = fn() { x }; let x = 2; f()", 2},
+    }
+
+    runVmTests(t, tests)
+}
+
+// the optimizations must not change what a program computes
+func TestOptimizationsAreUnobservable(t *testing.T) {
+    tests := []vmTestCase{
+        {`"a" + "b" == "ab"`, true},
+        {`"x" == "x"`, true},
+        {`"x" != "y"`, true},
+        {`let f = fn(s) { s == "ab" }; f("a" + "b")`, true},
+        {`bytes("ab") == bytes("a") + bytes("b")`, true},
+        {`bytes("ab") != bytes("ab")`, false},
+        {"let c = fn() { false }; let x = 1; if (c()) { let x = 2 }; x", 1},
+        {"let f = fn(a) { if (1 > 2) { let x = 1; }; if (a) { return 7; }; x }; f(true)", 7},
+        {"let f = fn(a) { if (a) { return 7; let x = 1; }; x }; f(true)", 7},
+    }
+
+    optimizations := []compiler.Optimizations{
+        {},
+        {ConstantFolding: true},
+        {DeadCode: true},
+        compiler.AllOptimizations(),
+    }
+
+    for _, o := range optimizations {
+        for _, tt := range tests {
+            comp := compiler.New()
+            comp.SetOptimizations(o)
+            err := comp.Compile(parse(tt.input))
+            if err != nil {
+                t.Fatalf("compiler error: %s", err)
+            }
+
+            vm := New(comp.Bytecode())
+            err = vm.Run()
+            if err != nil {
+                t.Fatalf("vm error: %s", err)
+            }
+
+            testExpectedObject(t, tt.expected, vm.LastPoppedStackElem())
+        }
+    }
+}
+
+func TestPeepholeOptimizedFunctions(t *testing.T) {
+    tests := []vmTestCase{
+        {"let f = fn(x) { if (x) { 1 }; 2 }; f(true) + f(false)", 4},
+        {"let f = fn(x) { if (x) { 1 } }; f(false)", Null},
+        {"let f = fn(x) { if (x) { if (x > 1) { 10 } else { 20 } } else { 30 } }; [f(2), f(1), f(false)]",
+            []int{10, 20, 30}},
+        {"let f = fn(x) { if (true) { x } else { 0 } }; f(5)", 5},
+        {"let f = fn(x) { if (x) { return 1; } else { return 2; }; 3 }; f(false)", 2},
+    }
+
+    runVmTests(t, tests)
+}
+
+func TestDeadCodeElimination(t *testing.T) {
+    tests := []vmTestCase{
+        {"let f = fn() { return 1; 2 }; f()", 1},
+        {"let f = fn(x) { if (x) { return 1; } else { return 2; }; 3 }; [f(true), f(false)]", []int{1, 2}},
+        {"let f = fn(x) { if (x) { return 1; }; 2 }; [f(true), f(false)]", []int{1, 2}},
+        {"let f = fn() { if (true) { return 1; }; 2 }; f()", 1},
+        {"let f = fn() { if (false) { return 1; }; 2 }; f()", 2},
+    }
+
+    runVmTests(t, tests)
+}
+
+func TestTailCalls(t *testing.T) {
+    tests := []vmTestCase{
+        {`
+        let sum = fn(self, n, acc) {
+            if (n == 0) { acc } else { self(self, n - 1, acc + n) }
+        };
+        sum(sum, 100000, 0)
+        `, 5000050000},
+        {`
+        let even = fn(even, odd, n) { if (n == 0) { return true; }; odd(even, odd, n - 1) };
+        let odd  = fn(even, odd, n) { if (n == 0) { return false; }; even(even, odd, n - 1) };
+        even(even, odd, 5001)
+        `, false},
+        {"let f = fn(x) { len(x) }; f([1, 2])", 2},
+        {"let f = fn(g) { g(1) }; f(fn(x) { x + 1 }) + 1", 3},
+    }
+
+    runVmTests(t, tests)
+}
+
+func TestStackOverflow(t *testing.T) {
+    input := `
+    let depth = fn(self, n) { if (n == 0) { 0 } else { 1 + self(self, n - 1) } };
+    depth(depth, 100000)
+    `
+
+    comp := compiler.New()
+    err := comp.Compile(parse(input))
+    if err != nil {
+        t.Fatalf("compiler error: %s", err)
+    }
+
+    vm := New(comp.Bytecode())
+    err = vm.Run()
+    if err == nil || !strings.HasPrefix(err.Error(), "stack overflow") {
+        t.Fatalf("expected a stack overflow. got=%v", err)
+    }
+}
+
+func TestSuperinstructions(t *testing.T) {
+    tests := []vmTestCase{
+        {"let f = fn(a, b, c, d, e) { a + b + c + d + e }; f(1, 2, 3, 4, 5)", 15},
+        {"let f = fn(n) { n + 1 }; f(41)", 42},
+        {`let f = fn(s) { s + "b" }; f("a")`, "ab"},
+        {"let f = fn(a, b) { if (a < b) { 1 } else { 2 } }; [f(1, 2), f(2, 1), f(2, 2)]", []int{1, 2, 2}},
+        {"let f = fn() { 5 }; let g = fn(x) { x * 2 }; f() + g(f())", 15},
+        {"let f = fn(x) { x + if (x < 0) { 1 } else { 2 } }; [f(-5), f(5)]", []int{-4, 7}},
+    }
+
+    runVmTests(t, tests)
+}
+
+func TestSuperinstructionErrors(t *testing.T) {
+    tests := []struct {
+        input    string
+        expected string
+    }{
+        {`let f = fn(x) { x + 1 }; f("a")`, "unsupported types for binary operation: STRING INTEGER"},
+        {"let f = fn(x) { if (x < true) { 1 } }; f(false)", "unknown operator: 9 (BOOLEAN BOOLEAN)"},
+    }
+
+    for _, tt := range tests {
+        comp := compiler.New()
+        err := comp.Compile(parse(tt.input))
+        if err != nil {
+            t.Fatalf("compiler error: %s", err)
+        }
+
+        vm := New(comp.Bytecode())
+        err = vm.Run()
+        if err == nil || err.Error() != tt.expected {
+            t.Errorf("%s: wrong error. want=%q, got=%v", tt.input, tt.expected, err)
+        }
+    }
+}
+
+func BenchmarkFib(b *testing.B) {
+    benchmarkSuperinstructions(b, `
+    let fib = fn(fib, n) { if (n < 2) { n } else { fib(fib, n - 1) + fib(fib, n - 2) } };
+    fib(fib, 20)
+    `)
+}
+
+func BenchmarkLoop(b *testing.B) {
+    benchmarkSuperinstructions(b, `
+    let loop = fn(loop, i, acc) { if (i < 100000) { loop(loop, i + 1, acc + i) } else { acc } };
+    loop(loop, 0, 0)
+    `)
+}
+
+// counts within the range of preallocated integers, see object.NewInteger
+func BenchmarkSmallIntegers(b *testing.B) {
+    comp := compiler.New()
+    err := comp.Compile(parse(`
+    let count = fn(count, i) { if (i < 1000) { count(count, i + 1) } else { i * 2 - i } };
+    count(count, 0)
+    `))
+    if err != nil {
+        b.Fatalf("compiler error: %s", err)
+    }
+    bytecode := comp.Bytecode()
+
+    b.ReportAllocs()
+    b.ResetTimer()
+    for i := 0; i < b.N; i++ {
+        vm := New(bytecode)
+        err = vm.Run()
+        if err != nil {
+            b.Fatalf("vm error: %s", err)
+        }
+    }
+}
+
+// runs input with and without superinstructions, all else optimized
+func benchmarkSuperinstructions(b *testing.B, input string) {
+    plain := compiler.AllOptimizations()
+    plain.Superinstructions = false
+
+    variants := []struct {
+        name          string
+        optimizations compiler.Optimizations
+    }{
+        {"plain", plain},
+        {"superinstructions", compiler.AllOptimizations()},
+    }
+
+    for _, v := range variants {
+        b.Run(v.name, func(b *testing.B) {
+            comp := compiler.New()
+            comp.SetOptimizations(v.optimizations)
+            err := comp.Compile(parse(input))
+            if err != nil {
+                b.Fatalf("compiler error: %s", err)
+            }
+            bytecode := comp.Bytecode()
+
+            b.ResetTimer()
+            for i := 0; i < b.N; i++ {
+                vm := New(bytecode)
+                err = vm.Run()
+                if err != nil {
+                    b.Fatalf("vm error: %s", err)
+                }
+            }
+        })
+    }
+}
+
 func runVmTests(t *testing.T, tests []vmTestCase) {
     t.Helper()
 